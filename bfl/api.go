@@ -13,6 +13,27 @@ import (
 type Client struct {
 	Key     string
 	BaseURL string
+	// HTTP client used for every request. Falls back to http.DefaultClient when nil.
+	HTTPClient *http.Client
+	// User agent sent with every request. Left to the HTTP client when empty.
+	UserAgent string
+	// Additional headers sent with every request.
+	Headers http.Header
+	// Timeout applied to each individual HTTP call, including reading the response body.
+	// Zero means no timeout beyond the context passed by the caller.
+	Timeout time.Duration
+	// Policy for retrying transient failures. Requests are not retried when nil.
+	RetryPolicy *RetryPolicy
+	// Default strategy used by Poll. Polls every second when nil.
+	PollStrategy PollStrategy
+	// Default maximum time Poll waits for a task to finish. Zero means no limit.
+	MaxPollWait time.Duration
+	// Refuse to submit tasks while the credit balance is below this threshold. Zero disables the check.
+	MinCredits float64
+
+	limiter *rateLimiter
+	tasks   *taskLimiter
+	credits creditsCache
 }
 
 func NewClient(key string, baseURL string) *Client {
@@ -22,6 +43,64 @@ func NewClient(key string, baseURL string) *Client {
 	}
 }
 
+// Create a client and apply the given options to it.
+func NewClientWithOptions(key string, baseURL string, opts ...ClientOption) *Client {
+	c := NewClient(key, baseURL)
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// Perform a single HTTP call against the API and read the whole response body.
+// The response body is closed before returning.
+func (c *Client) send(ctx context.Context, method string, url string, payload []byte) (*http.Response, []byte, error) {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return nil, nil, err
+	}
+	for name, values := range c.Headers {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Key != "" {
+		req.Header.Set("X-Key", c.Key)
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	res, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return res, body, nil
+}
+
 type AsyncResponse struct {
 	ID         string `json:"id"`
 	PollingURL string `json:"polling_url"`
@@ -66,6 +145,20 @@ type ResultResponse[T Result, D Details] struct {
 	Result   T              `json:"result"`
 	Progress float64        `json:"progress"`
 	Details  D              `json:"details"`
+	// The response exactly as returned by the API, including fields not modelled above.
+	Raw json.RawMessage `json:"-"`
+}
+
+type resultResponseFields[T Result, D Details] ResultResponse[T, D]
+
+func (r *ResultResponse[T, D]) UnmarshalJSON(data []byte) error {
+	var v resultResponseFields[T, D]
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	v.Raw = append(json.RawMessage(nil), data...)
+	*r = ResultResponse[T, D](v)
+	return nil
 }
 
 type StatusResponse string
@@ -89,31 +182,123 @@ func (e *ValidationError) Error() string {
 	return fmt.Sprintf("Validation error: %v", e.Msg)
 }
 
+// Returned when a task was rejected by input or output moderation.
+type ModerationError struct {
+	TaskID string
+	// Either StatusRequestModerated or StatusContentModerated.
+	Status StatusResponse
+	// The details reported by the API alongside the moderated status, if any.
+	Details any
+}
+
+func (e *ModerationError) Error() string {
+	return fmt.Sprintf("task %s: %s", e.TaskID, e.Status)
+}
+
+// Returned when the API reports that a task failed while being processed.
+type TaskFailedError struct {
+	TaskID  string
+	Details any
+}
+
+func (e *TaskFailedError) Error() string {
+	return fmt.Sprintf("task %s: failed", e.TaskID)
+}
+
+// Returned when the API does not know about the requested task.
+type TaskNotFoundError struct {
+	TaskID string
+}
+
+func (e *TaskNotFoundError) Error() string {
+	return fmt.Sprintf("task %s: not found", e.TaskID)
+}
+
+// Whether the status is final, meaning the task will not change state anymore.
+func (s StatusResponse) IsTerminal() bool {
+	switch s {
+	case StatusReady, StatusError, StatusRequestModerated, StatusContentModerated, StatusTaskNotFound:
+		return true
+	default:
+		return false
+	}
+}
+
+// Returns the error corresponding to a terminal status other than StatusReady, or nil.
+func (r *ResultResponse[T, D]) Err() error {
+	switch r.Status {
+	case StatusRequestModerated, StatusContentModerated:
+		return &ModerationError{TaskID: r.ID, Status: r.Status, Details: r.Details}
+	case StatusError:
+		return &TaskFailedError{TaskID: r.ID, Details: r.Details}
+	case StatusTaskNotFound:
+		return &TaskNotFoundError{TaskID: r.ID}
+	default:
+		return nil
+	}
+}
+
+// Return a pointer to the given value, for setting optional task parameters.
+//
+// Task parameters for which the zero value is a meaningful setting, such as a SafetyTolerance
+// of 0 or a Seed of 0, are pointers: nil leaves them unset so that the API default applies.
+// Other parameters are left unset by their zero value.
+func Ptr[T any](v T) *T {
+	return &v
+}
+
 type AsyncTask interface {
 	GetActionURL(baseURL string) string
 }
 
+// Implemented by tasks that can check their parameters before being submitted.
+type validatable interface {
+	Validate() error
+}
+
 func (c *Client) AsyncRequest(ctx context.Context, task AsyncTask) (*AsyncResponse, error) {
 	if c.Key == "" {
 		return nil, fmt.Errorf("API key is not set")
 	}
+	if t, ok := task.(validatable); ok {
+		if err := t.Validate(); err != nil {
+			return nil, err
+		}
+	}
 	url := task.GetActionURL(c.BaseURL)
 	data, err := json.Marshal(task)
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(data))
-	if err != nil {
+	if err := c.checkMinCredits(ctx); err != nil {
 		return nil, err
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Key", c.Key)
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
+	if c.limiter != nil {
+		if err := c.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
 	}
-	defer res.Body.Close()
-	body, err := io.ReadAll(res.Body)
+	if c.tasks != nil {
+		if err := c.tasks.acquire(ctx); err != nil {
+			return nil, err
+		}
+	}
+	ar, err := c.submit(ctx, url, data)
+	if c.MinCredits > 0 {
+		c.credits.invalidate()
+	}
+	if c.tasks != nil {
+		if err != nil {
+			c.tasks.abandon()
+		} else {
+			c.tasks.bind(ar.ID)
+		}
+	}
+	return ar, err
+}
+
+func (c *Client) submit(ctx context.Context, url string, data []byte) (*AsyncResponse, error) {
+	res, body, err := c.do(ctx, "POST", url, data, false)
 	if err != nil {
 		return nil, err
 	}
@@ -125,29 +310,20 @@ func (c *Client) AsyncRequest(ctx context.Context, task AsyncTask) (*AsyncRespon
 			return nil, err
 		}
 		return &ar, nil
-	case 422:
-		var httpValidationError HTTPValidationError
-		if err = json.Unmarshal(body, &httpValidationError); err != nil {
-			return nil, err
-		}
-		return nil, &httpValidationError
 	default:
-		return nil, fmt.Errorf("status code: %d, body: %s", res.StatusCode, string(body))
+		return nil, responseError(res, body)
 	}
 }
 
 func GetResult[T Result, D Details](ctx context.Context, c *Client, taskID string) (*ResultResponse[T, D], error) {
 	url := fmt.Sprintf("%s/v1/get_result?id=%s", c.BaseURL, taskID)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer res.Body.Close()
-	body, err := io.ReadAll(res.Body)
+	return fetchResult[T, D](ctx, c, url, taskID)
+}
+
+// Request the current state of a task from the given URL.
+// The concurrency slot of the task is released once it reaches a terminal status.
+func fetchResult[T Result, D Details](ctx context.Context, c *Client, url string, taskID string) (*ResultResponse[T, D], error) {
+	res, body, err := c.do(ctx, "GET", url, nil, true)
 	if err != nil {
 		return nil, err
 	}
@@ -158,65 +334,53 @@ func GetResult[T Result, D Details](ctx context.Context, c *Client, taskID strin
 		if err != nil {
 			return nil, err
 		}
-		return &resultResponse, nil
-	case 422:
-		var httpValidationError HTTPValidationError
-		if err = json.Unmarshal(body, &httpValidationError); err != nil {
-			return nil, err
+		if resultResponse.ID == "" {
+			resultResponse.ID = taskID
+		}
+		if resultResponse.Status.IsTerminal() {
+			c.ReleaseTask(taskID)
 		}
-		return nil, &httpValidationError
+		return &resultResponse, nil
 	default:
-		return nil, fmt.Errorf("status code: %d, body: %s", res.StatusCode, string(body))
+		return nil, responseError(res, body)
 	}
 }
 
-// Poll the BFL API for the result of an async task every second.
-func Poll[T Result, D Details](ctx context.Context, c *Client, ar *AsyncResponse, verbose bool) (*ResultResponse[T, D], error) {
-	sleepTimeSeconds := 1
-	attempts := 0
-	for {
-		req, err := http.NewRequestWithContext(ctx, "GET", ar.PollingURL, nil)
-		if err != nil {
-			return nil, err
-		}
-		res, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return nil, err
-		}
-		defer res.Body.Close()
-		body, err := io.ReadAll(res.Body)
-		if err != nil {
-			return nil, err
+// The error for an unsuccessful response: the validation errors of an HTTP 422, an *APIError otherwise.
+func responseError(res *http.Response, body []byte) error {
+	if res.StatusCode == http.StatusUnprocessableEntity {
+		var httpValidationError HTTPValidationError
+		if err := json.Unmarshal(body, &httpValidationError); err != nil {
+			return err
 		}
-		switch res.StatusCode {
-		case 200:
-			var resultResponse ResultResponse[T, D]
-			err = json.Unmarshal(body, &resultResponse)
-			if err != nil {
-				return nil, err
-			}
-			if resultResponse.Status == StatusReady {
-				return &resultResponse, nil
-			}
-		case 422:
-			var httpValidationError HTTPValidationError
-			if err = json.Unmarshal(body, &httpValidationError); err != nil {
-				return nil, err
-			}
-			return nil, &httpValidationError
-		default:
-			return nil, fmt.Errorf("status code: %d, body: %s", res.StatusCode, string(body))
+		return &httpValidationError
+	}
+	return newAPIError(res, body)
+}
+
+// Perform an authenticated request against the API and decode the JSON response into out.
+func (c *Client) requestJSON(ctx context.Context, method string, url string, payload any, out any) error {
+	if c.Key == "" {
+		return fmt.Errorf("API key is not set")
+	}
+	var data []byte
+	if payload != nil {
+		var err error
+		if data, err = json.Marshal(payload); err != nil {
+			return err
 		}
-		select {
-		case <-time.After(time.Duration(sleepTimeSeconds) * time.Second):
-			attempts++
-			if verbose {
-				if attempts%10 == 0 {
-					fmt.Printf("Polling for result... (Wait time: %d seconds)\n", sleepTimeSeconds*attempts)
-				}
-			}
-		case <-ctx.Done():
-			return nil, ctx.Err()
+	}
+	res, body, err := c.do(ctx, method, url, data, method == "GET")
+	if err != nil {
+		return err
+	}
+	switch res.StatusCode {
+	case 200:
+		if out == nil {
+			return nil
 		}
+		return json.Unmarshal(body, out)
+	default:
+		return responseError(res, body)
 	}
 }
@@ -0,0 +1,135 @@
+package bfl
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Limits of the image sizes accepted by the models taking a width and height.
+const (
+	MinDimension = 256
+	MaxDimension = 1440
+)
+
+// An aspect ratio such as "16:9".
+type AspectRatio string
+
+// Common aspect ratios.
+const (
+	AspectRatio1x1  AspectRatio = "1:1"
+	AspectRatio4x3  AspectRatio = "4:3"
+	AspectRatio3x2  AspectRatio = "3:2"
+	AspectRatio16x9 AspectRatio = "16:9"
+	AspectRatio21x9 AspectRatio = "21:9"
+	AspectRatio3x4  AspectRatio = "3:4"
+	AspectRatio2x3  AspectRatio = "2:3"
+	AspectRatio9x16 AspectRatio = "9:16"
+	AspectRatio9x21 AspectRatio = "9:21"
+)
+
+// Parse an aspect ratio written as "width:height" with positive integers.
+func ParseAspectRatio(s string) (AspectRatio, error) {
+	a := AspectRatio(strings.ReplaceAll(s, " ", ""))
+	if _, _, err := a.Ratio(); err != nil {
+		return "", err
+	}
+	return a, nil
+}
+
+// The width and height terms of the aspect ratio.
+func (a AspectRatio) Ratio() (int, int, error) {
+	ws, hs, ok := strings.Cut(string(a), ":")
+	w, errW := strconv.Atoi(strings.TrimSpace(ws))
+	h, errH := strconv.Atoi(strings.TrimSpace(hs))
+	if !ok || errW != nil || errH != nil || w <= 0 || h <= 0 {
+		return 0, 0, fmt.Errorf("invalid aspect ratio %q", string(a))
+	}
+	return w, h, nil
+}
+
+// The aspect ratio as width divided by height.
+func (a AspectRatio) Float() (float64, error) {
+	w, h, err := a.Ratio()
+	if err != nil {
+		return 0, err
+	}
+	return float64(w) / float64(h), nil
+}
+
+// Check that the aspect ratio is well-formed and between 21:9 and 9:21, as required by the Ultra models.
+func (a AspectRatio) Validate() error {
+	r, err := a.Float()
+	if err != nil {
+		return err
+	}
+	if r > 21.0/9.0 || r < 9.0/21.0 {
+		return fmt.Errorf("aspect ratio %s is not between 21:9 and 9:21", string(a))
+	}
+	return nil
+}
+
+// Solve the width and height closest to the aspect ratio and the given area in megapixels
+// (millions of pixels) for the models that take a width and height: multiples of 32 between
+// 256 and 1440. Candidates are scored on the relative error of their aspect ratio and of their
+// area, the former weighing four times as much, so a slightly less exact ratio may be chosen
+// when it lands much closer to the requested area.
+func (a AspectRatio) Dimensions(megapixels float64) (int, int, error) {
+	r, err := a.Float()
+	if err != nil {
+		return 0, 0, err
+	}
+	if megapixels <= 0 {
+		return 0, 0, fmt.Errorf("megapixels must be positive")
+	}
+	area := megapixels * 1e6
+	bestW, bestH, bestScore := 0, 0, math.Inf(1)
+	for w := MinDimension; w <= MaxDimension; w += 32 {
+		for h := MinDimension; h <= MaxDimension; h += 32 {
+			ratioErr := math.Abs(math.Log(float64(w) / float64(h) / r))
+			areaErr := math.Abs(math.Log(float64(w*h) / area))
+			if score := 4*ratioErr + areaErr; score < bestScore {
+				bestW, bestH, bestScore = w, h, score
+			}
+		}
+	}
+	return bestW, bestH, nil
+}
+
+// Lay out a generation task with the given aspect ratio. Ultra tasks take the aspect ratio as is,
+// while tasks taking a width and height get the dimensions solved for the given megapixels.
+// Tasks whose size is determined by an input image are rejected.
+func ApplyAspectRatio(task GenerateTask, a AspectRatio, megapixels float64) error {
+	var width, height *int
+	switch t := task.(type) {
+	case *FluxPro11UltraGenerate:
+		if err := a.Validate(); err != nil {
+			return err
+		}
+		t.AspectRatio = a
+		return nil
+	case *FluxPro11UltraFinetunedGenerate:
+		if err := a.Validate(); err != nil {
+			return err
+		}
+		t.AspectRatio = a
+		return nil
+	case *FluxPro11Generate:
+		width, height = &t.Width, &t.Height
+	case *FluxProGenerate:
+		width, height = &t.Width, &t.Height
+	case *FluxDevGenerate:
+		width, height = &t.Width, &t.Height
+	case *FluxProFinetunedGenerate:
+		width, height = &t.Width, &t.Height
+	default:
+		return fmt.Errorf("the size of %T is determined by its input image", task)
+	}
+	w, h, err := a.Dimensions(megapixels)
+	if err != nil {
+		return err
+	}
+	*width, *height = w, h
+	return nil
+}
@@ -0,0 +1,101 @@
+package bfl
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// How long a credit balance fetched for the pre-flight check is reused.
+const creditsCacheDuration = 30 * time.Second
+
+// Returned before submitting a task when the credit balance is below the client's threshold.
+type InsufficientCreditsError struct {
+	Balance   float64
+	Threshold float64
+}
+
+func (e *InsufficientCreditsError) Error() string {
+	return fmt.Sprintf("insufficient credits: balance %g is below %g", e.Balance, e.Threshold)
+}
+
+func (e *InsufficientCreditsError) Is(target error) bool {
+	return target == ErrInsufficientCredits
+}
+
+// The balance used by the pre-flight check. It is invalidated after every submission, as the
+// submission may have spent credits.
+type creditsCache struct {
+	mu        sync.Mutex
+	balance   float64
+	fetchedAt time.Time
+	// Incremented on invalidation, so that a fetch started before a submission is not cached.
+	generation int
+}
+
+func (cc *creditsCache) get() (float64, int, bool) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	fresh := !cc.fetchedAt.IsZero() && time.Since(cc.fetchedAt) <= creditsCacheDuration
+	return cc.balance, cc.generation, fresh
+}
+
+func (cc *creditsCache) set(balance float64, generation int) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if cc.generation == generation {
+		cc.balance = balance
+		cc.fetchedAt = time.Now()
+	}
+}
+
+func (cc *creditsCache) invalidate() {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.fetchedAt = time.Time{}
+	cc.generation++
+}
+
+// Get the remaining credit balance of the account.
+func (c *Client) Credits(ctx context.Context) (float64, error) {
+	var res struct {
+		Credits float64 `json:"credits"`
+	}
+	if err := c.requestJSON(ctx, "GET", fmt.Sprintf("%s/v1/credits", c.BaseURL), nil, &res); err != nil {
+		return 0, err
+	}
+	return res.Credits, nil
+}
+
+// Fail with an *InsufficientCreditsError if the credit balance is below the given threshold.
+func (c *Client) CheckCredits(ctx context.Context, threshold float64) error {
+	balance, err := c.Credits(ctx)
+	if err != nil {
+		return err
+	}
+	if balance < threshold {
+		return &InsufficientCreditsError{Balance: balance, Threshold: threshold}
+	}
+	return nil
+}
+
+// Check the balance against MinCredits before a submission, reusing the balance fetched for
+// previous checks until a task is submitted.
+func (c *Client) checkMinCredits(ctx context.Context) error {
+	if c.MinCredits <= 0 {
+		return nil
+	}
+	balance, generation, fresh := c.credits.get()
+	if !fresh {
+		var err error
+		if balance, err = c.Credits(ctx); err != nil {
+			return err
+		}
+		c.credits.set(balance, generation)
+	}
+	if balance < c.MinCredits {
+		return &InsufficientCreditsError{Balance: balance, Threshold: c.MinCredits}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package bfl
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// File extensions of the images accepted in finetuning datasets.
+var DatasetImageExtensions = []string{".jpg", ".jpeg", ".png", ".webp"}
+
+// An image of a finetuning dataset and its optional caption.
+type DatasetImage struct {
+	// Path of the image on disk.
+	Path string
+	// Path of the caption file on disk, empty if the image has no caption.
+	CaptionPath string
+}
+
+// A set of training images for finetuning, built from local files.
+type Dataset struct {
+	Images []DatasetImage
+	// Caption files without a matching image. They are not included in the archive.
+	OrphanCaptions []string
+}
+
+func isDatasetImage(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range DatasetImageExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+func isCaption(path string) bool {
+	return strings.ToLower(filepath.Ext(path)) == ".txt"
+}
+
+func stem(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// Build a dataset from the images in a directory, pairing each with the caption file of the
+// same name, e.g. "cat.jpg" with "cat.txt". Subdirectories and other files are ignored.
+func NewDatasetFromDir(dir string) (*Dataset, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return NewDatasetFromFiles(paths...)
+}
+
+// Build a dataset from a list of image and caption files. Images without a caption in the list
+// are paired with a caption file of the same name next to them, if there is one.
+func NewDatasetFromFiles(paths ...string) (*Dataset, error) {
+	captions := make(map[string]string)
+	var images []string
+	for _, path := range paths {
+		switch {
+		case isCaption(path):
+			captions[stem(path)] = path
+		case isDatasetImage(path):
+			images = append(images, path)
+		}
+	}
+	sort.Strings(images)
+	d := &Dataset{}
+	names := make(map[string]string)
+	for _, path := range images {
+		name := stem(path)
+		if other, ok := names[name]; ok {
+			return nil, fmt.Errorf("images %s and %s have the same name", other, path)
+		}
+		names[name] = path
+		image := DatasetImage{Path: path}
+		if caption, ok := captions[name]; ok {
+			image.CaptionPath = caption
+			delete(captions, name)
+		} else if caption := strings.TrimSuffix(path, filepath.Ext(path)) + ".txt"; fileExists(caption) {
+			image.CaptionPath = caption
+		}
+		d.Images = append(d.Images, image)
+	}
+	for _, caption := range captions {
+		d.OrphanCaptions = append(d.OrphanCaptions, caption)
+	}
+	sort.Strings(d.OrphanCaptions)
+	return d, nil
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// Create the ZIP archive expected by the API, with every image and caption at its root.
+func (d *Dataset) Zip() ([]byte, error) {
+	if len(d.Images) == 0 {
+		return nil, fmt.Errorf("dataset has no images")
+	}
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, image := range d.Images {
+		name := stem(image.Path)
+		if err := addZipFile(w, name+strings.ToLower(filepath.Ext(image.Path)), image.Path); err != nil {
+			return nil, err
+		}
+		if image.CaptionPath != "" {
+			if err := addZipFile(w, name+".txt", image.CaptionPath); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func addZipFile(w *zip.Writer, name string, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	f, err := w.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+// The base64-encoded ZIP archive of the dataset, as expected by FluxFinetune.FileData.
+func (d *Dataset) Base64() (string, error) {
+	data, err := d.Zip()
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// Set the dataset as the training data of a finetuning task.
+func (d *Dataset) Apply(task *FluxFinetune) error {
+	data, err := d.Base64()
+	if err != nil {
+		return err
+	}
+	task.FileData = data
+	return nil
+}
@@ -0,0 +1,214 @@
+package bfl
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	_ "golang.org/x/image/webp"
+)
+
+type LintSeverity string
+
+const (
+	// The dataset should not be submitted.
+	LintError LintSeverity = "error"
+	// The dataset can be submitted, but may not give the expected results.
+	LintWarning LintSeverity = "warning"
+)
+
+// Codes identifying the kind of a lint issue.
+const (
+	LintTooFewImages       = "too_few_images"
+	LintTooManyImages      = "too_many_images"
+	LintUnsupportedFormat  = "unsupported_format"
+	LintCorruptImage       = "corrupt_image"
+	LintLowResolution      = "low_resolution"
+	LintArchiveTooLarge    = "archive_too_large"
+	LintDuplicateImage     = "duplicate_image"
+	LintOrphanCaption      = "orphan_caption"
+	LintEmptyCaption       = "empty_caption"
+	LintMissingTriggerWord = "missing_trigger_word"
+	LintUnreadableFile     = "unreadable_file"
+)
+
+// A problem found in a finetuning dataset.
+type LintIssue struct {
+	Severity LintSeverity `json:"severity"`
+	Code     string       `json:"code"`
+	// The file the issue is about, empty for issues about the whole dataset.
+	File    string `json:"file,omitempty"`
+	Message string `json:"message"`
+}
+
+// The outcome of linting a finetuning dataset.
+type LintReport struct {
+	Images int         `json:"images"`
+	Issues []LintIssue `json:"issues"`
+}
+
+func (r *LintReport) add(severity LintSeverity, code string, file string, format string, args ...any) {
+	r.Issues = append(r.Issues, LintIssue{
+		Severity: severity,
+		Code:     code,
+		File:     file,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (r *LintReport) filter(severity LintSeverity) []LintIssue {
+	var issues []LintIssue
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+func (r *LintReport) Errors() []LintIssue {
+	return r.filter(LintError)
+}
+
+func (r *LintReport) Warnings() []LintIssue {
+	return r.filter(LintWarning)
+}
+
+func (r *LintReport) HasErrors() bool {
+	return len(r.Errors()) > 0
+}
+
+// Limits checked when linting a finetuning dataset. Zero values use the defaults.
+type LintOptions struct {
+	// Default: 5.
+	MinImages int
+	// Default: 100.
+	MaxImages int
+	// Minimum length in pixels of the shorter side of each image.
+	// Default: 512.
+	MinResolution int
+	// Maximum size in bytes of the ZIP archive, before base64 encoding. The size is estimated
+	// from the size of the files, as images barely shrink when compressed.
+	// Default: 100 MiB.
+	MaxArchiveBytes int
+	// Captions are expected to contain this word when set.
+	TriggerWord string
+}
+
+func (o LintOptions) withDefaults() LintOptions {
+	if o.MinImages <= 0 {
+		o.MinImages = 5
+	}
+	if o.MaxImages <= 0 {
+		o.MaxImages = 100
+	}
+	if o.MinResolution <= 0 {
+		o.MinResolution = 512
+	}
+	if o.MaxArchiveBytes <= 0 {
+		o.MaxArchiveBytes = 100 << 20
+	}
+	return o
+}
+
+// Lint the dataset before using it for the given finetuning task, taking the trigger word from the task.
+func (d *Dataset) LintFor(task *FluxFinetune, opts LintOptions) *LintReport {
+	if opts.TriggerWord == "" {
+		opts.TriggerWord = task.TriggerWord
+	}
+	return d.Lint(opts)
+}
+
+// Check the dataset for problems that would make finetuning fail or give poor results.
+func (d *Dataset) Lint(opts LintOptions) *LintReport {
+	opts = opts.withDefaults()
+	r := &LintReport{Images: len(d.Images), Issues: []LintIssue{}}
+	if n := len(d.Images); n < opts.MinImages {
+		r.add(LintError, LintTooFewImages, "", "dataset has %d images, at least %d are required", n, opts.MinImages)
+	} else if n > opts.MaxImages {
+		r.add(LintError, LintTooManyImages, "", "dataset has %d images, at most %d are allowed", n, opts.MaxImages)
+	}
+	seen := make(map[[sha256.Size]byte]string)
+	archiveSize := zipEndSize
+	for _, img := range d.Images {
+		data, err := os.ReadFile(img.Path)
+		if err != nil {
+			r.add(LintError, LintUnreadableFile, img.Path, "unable to read image: %v", err)
+			continue
+		}
+		name := stem(img.Path)
+		archiveSize += zipEntrySize(name+filepath.Ext(img.Path), len(data))
+		sum := sha256.Sum256(data)
+		if other, ok := seen[sum]; ok {
+			r.add(LintWarning, LintDuplicateImage, img.Path, "image is identical to %s", other)
+		} else {
+			seen[sum] = img.Path
+		}
+		lintImage(r, img.Path, data, opts)
+		if img.CaptionPath != "" {
+			archiveSize += zipEntrySize(name+".txt", lintCaption(r, img.CaptionPath, opts))
+		}
+	}
+	for _, caption := range d.OrphanCaptions {
+		r.add(LintWarning, LintOrphanCaption, caption, "caption has no matching image and will be ignored")
+	}
+	if archiveSize > opts.MaxArchiveBytes {
+		r.add(LintError, LintArchiveTooLarge, "", "archive is about %d bytes, at most %d are allowed", archiveSize, opts.MaxArchiveBytes)
+	}
+	return r
+}
+
+// Sizes of the records of a ZIP archive, besides the file names and contents.
+const (
+	zipLocalHeaderSize    = 30
+	zipDataDescriptorSize = 16
+	zipCentralHeaderSize  = 46
+	zipEndSize            = 22
+)
+
+// The size taken by a file in a ZIP archive, assuming its content is stored as is.
+func zipEntrySize(name string, size int) int {
+	return zipLocalHeaderSize + zipDataDescriptorSize + zipCentralHeaderSize + 2*len(name) + size
+}
+
+func lintImage(r *LintReport, path string, data []byte, opts LintOptions) {
+	var width, height int
+	switch http.DetectContentType(data) {
+	case "image/jpeg", "image/png", "image/webp":
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			r.add(LintError, LintCorruptImage, path, "unable to decode image: %v", err)
+			return
+		}
+		width, height = img.Bounds().Dx(), img.Bounds().Dy()
+	default:
+		r.add(LintError, LintUnsupportedFormat, path, "file is not a JPEG, PNG or WebP image")
+		return
+	}
+	if min(width, height) < opts.MinResolution {
+		r.add(LintWarning, LintLowResolution, path, "image is %dx%d, at least %d pixels per side are recommended", width, height, opts.MinResolution)
+	}
+}
+
+// Lint a caption file and return its size.
+func lintCaption(r *LintReport, path string, opts LintOptions) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		r.add(LintError, LintUnreadableFile, path, "unable to read caption: %v", err)
+		return 0
+	}
+	caption := strings.TrimSpace(string(data))
+	if caption == "" {
+		r.add(LintWarning, LintEmptyCaption, path, "caption is empty")
+	} else if opts.TriggerWord != "" && !strings.Contains(caption, opts.TriggerWord) {
+		r.add(LintWarning, LintMissingTriggerWord, path, "caption does not contain the trigger word %q", opts.TriggerWord)
+	}
+	return len(data)
+}
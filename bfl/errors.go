@@ -0,0 +1,96 @@
+package bfl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Kinds of API errors, to be used with errors.Is.
+var (
+	// The API key is missing, invalid or not allowed to perform the request (HTTP 401 and 403).
+	ErrAuthentication = errors.New("authentication failed")
+	// The account does not have enough credits (HTTP 402).
+	ErrInsufficientCredits = errors.New("insufficient credits")
+	// Too many requests or active tasks (HTTP 429).
+	ErrRateLimited = errors.New("rate limited")
+	// The API failed to handle the request (HTTP 5xx).
+	ErrServer = errors.New("server error")
+)
+
+// Returned when the API responds with an unexpected status code.
+// Validation failures (HTTP 422) are reported as *HTTPValidationError instead.
+type APIError struct {
+	StatusCode int
+	// Method and URL path of the request, without the query.
+	Method   string
+	Endpoint string
+	// Value of the X-Request-Id response header, if any.
+	RequestID string
+	// The raw response body.
+	Body []byte
+	// The decoded detail field of the response body, if it is JSON.
+	Detail any
+}
+
+func newAPIError(res *http.Response, body []byte) *APIError {
+	e := &APIError{
+		StatusCode: res.StatusCode,
+		RequestID:  res.Header.Get("X-Request-Id"),
+		Body:       body,
+	}
+	if res.Request != nil && res.Request.URL != nil {
+		e.Method = res.Request.Method
+		e.Endpoint = res.Request.URL.Scheme + "://" + res.Request.URL.Host + res.Request.URL.Path
+	}
+	var parsed struct {
+		Detail any `json:"detail"`
+	}
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		e.Detail = parsed.Detail
+	}
+	return e
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("status code: %d", e.StatusCode)
+	if e.Endpoint != "" {
+		msg = fmt.Sprintf("%s %s: %s", e.Method, e.Endpoint, msg)
+	}
+	switch detail := e.Detail.(type) {
+	case nil:
+		if len(e.Body) > 0 {
+			msg += fmt.Sprintf(", body: %s", string(e.Body))
+		}
+	case string:
+		msg += fmt.Sprintf(", detail: %s", detail)
+	default:
+		msg += fmt.Sprintf(", detail: %v", detail)
+	}
+	if e.RequestID != "" {
+		msg += fmt.Sprintf(" (request id: %s)", e.RequestID)
+	}
+	return msg
+}
+
+// The kind of the error, or nil if it has none.
+func (e *APIError) Kind() error {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized, e.StatusCode == http.StatusForbidden:
+		return ErrAuthentication
+	case e.StatusCode == http.StatusPaymentRequired:
+		return ErrInsufficientCredits
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode >= 500 && e.StatusCode < 600:
+		return ErrServer
+	default:
+		return nil
+	}
+}
+
+func (e *APIError) Is(target error) bool {
+	kind := e.Kind()
+	return kind != nil && kind == target
+}
@@ -2,7 +2,9 @@ package bfl
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/url"
 )
 
 type FinetuneMode string
@@ -36,27 +38,120 @@ const (
 	LoraRank32 LoraRank = 32
 )
 
-// TODO: unimplemented
-type FinetuneResult struct{}
+type FinetuneResult struct {
+	// ID of the fine-tuned model, to be used as FinetuneID when generating images.
+	FinetuneID string `json:"finetune_id"`
+	// Fields returned by the API that are not modelled above.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (r *FinetuneResult) UnmarshalJSON(data []byte) error {
+	type finetuneResult FinetuneResult
+	var v finetuneResult
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	extra, err := unknownFields(data, &v)
+	if err != nil {
+		return err
+	}
+	v.Extra = extra
+	*r = FinetuneResult(v)
+	return nil
+}
 
-// TODO: unimplemented
-type FinetuneDetails struct{}
+// Details of a fine-tuned model, as reported while finetuning and by FinetuneDetails.
+type FinetuneDetails struct {
+	FinetuneID      string           `json:"finetune_id,omitempty"`
+	Status          StatusResponse   `json:"status,omitempty"`
+	FinetuneComment string           `json:"finetune_comment,omitempty"`
+	TriggerWord     string           `json:"trigger_word,omitempty"`
+	Mode            FinetuneMode     `json:"mode,omitempty"`
+	Iterations      int              `json:"iterations,omitempty"`
+	LearningRate    float64          `json:"learning_rate,omitempty"`
+	Captioning      bool             `json:"captioning,omitempty"`
+	Priority        FinetunePriority `json:"priority,omitempty"`
+	FinetuneType    FinetuneType     `json:"finetune_type,omitempty"`
+	LoraRank        LoraRank         `json:"lora_rank,omitempty"`
+	// Unix timestamps in seconds.
+	CreatedAt float64 `json:"created_at,omitempty"`
+	StartTime float64 `json:"start_time,omitempty"`
+	EndTime   float64 `json:"end_time,omitempty"`
+	// Fields returned by the API that are not modelled above.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (d *FinetuneDetails) UnmarshalJSON(data []byte) error {
+	type finetuneDetails FinetuneDetails
+	var v finetuneDetails
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	extra, err := unknownFields(data, &v)
+	if err != nil {
+		return err
+	}
+	v.Extra = extra
+	*d = FinetuneDetails(v)
+	return nil
+}
 
 type FinetuneTask interface {
 	AsyncTask
 	FinetuneTaskMarker()
 }
 
-func Finetune(ctx context.Context, c *Client, task FinetuneTask) (*FinetuneResult, error) {
-	ar, err := c.AsyncRequest(ctx, task)
+// Submit a finetuning task, wait for it to finish and return the ID of the fine-tuned model.
+func Finetune(ctx context.Context, c *Client, task FinetuneTask, opts ...PollOption) (string, error) {
+	t, err := c.SubmitFinetune(ctx, task, opts...)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	result, err := Poll[*FinetuneResult, *FinetuneDetails](ctx, c, ar, true)
+	defer t.Stop()
+	result, err := t.Wait(ctx)
 	if err != nil {
+		return "", err
+	}
+	if result.Result != nil && result.Result.FinetuneID != "" {
+		return result.Result.FinetuneID, nil
+	}
+	// The ID of the fine-tuned model is the ID of the task that created it.
+	return result.ID, nil
+}
+
+// List the IDs of the fine-tuned models of the account.
+func (c *Client) ListFinetunes(ctx context.Context) ([]string, error) {
+	var res struct {
+		Finetunes []string `json:"finetunes"`
+	}
+	if err := c.requestJSON(ctx, "GET", fmt.Sprintf("%s/v1/my_finetunes", c.BaseURL), nil, &res); err != nil {
 		return nil, err
 	}
-	return result.Result, nil
+	return res.Finetunes, nil
+}
+
+// Get the details of a fine-tuned model.
+func (c *Client) GetFinetuneDetails(ctx context.Context, finetuneID string) (*FinetuneDetails, error) {
+	var res struct {
+		FinetuneDetails *FinetuneDetails `json:"finetune_details"`
+	}
+	u := fmt.Sprintf("%s/v1/finetune_details?finetune_id=%s", c.BaseURL, url.QueryEscape(finetuneID))
+	if err := c.requestJSON(ctx, "GET", u, nil, &res); err != nil {
+		return nil, err
+	}
+	if res.FinetuneDetails == nil {
+		return nil, fmt.Errorf("no details returned for finetune %s", finetuneID)
+	}
+	if res.FinetuneDetails.FinetuneID == "" {
+		res.FinetuneDetails.FinetuneID = finetuneID
+	}
+	return res.FinetuneDetails, nil
+}
+
+// Delete a fine-tuned model.
+func (c *Client) DeleteFinetune(ctx context.Context, finetuneID string) error {
+	payload := map[string]string{"finetune_id": finetuneID}
+	return c.requestJSON(ctx, "POST", fmt.Sprintf("%s/v1/delete_finetune", c.BaseURL), payload, nil)
 }
 
 // Task parameters for finetuning a flux model through the BFL API.
@@ -67,27 +162,27 @@ type FluxFinetune struct {
 	FinetuneComment string `json:"finetune_comment"`
 	// Trigger word for the fine-tuned model.
 	// Default: TOK.
-	TriggerWord string `json:"trigger_word"`
+	TriggerWord string `json:"trigger_word,omitempty"`
 	// Mode for the fine-tuned model. Allowed values are 'general', 'character', 'style', 'product'. This will affect the caption behaviour. General will describe the image in full detail.
 	Mode FinetuneMode `json:"mode"`
 	// Number of iterations for fine-tuning.
 	// Min: 100, Max: 1000, Default: 300.
-	Iterations int `json:"iterations"`
+	Iterations int `json:"iterations,omitempty"`
 	// Learning rate for fine-tuning. If not provided, defaults to 1e-5 for full fine-tuning and 1e-4 for lora fine-tuning.
 	// Min: 0.000001, Max: 0.005.
 	LearningRate float64 `json:"learning_rate,omitempty"`
 	// Whether to enable captioning during fine-tuning.
 	// Default: true.
-	Captioning bool `json:"captioning"`
+	Captioning *bool `json:"captioning,omitempty"`
 	// Priority of the fine-tuning process. 'speed' will prioritize iteration speed over quality, 'quality' will prioritize quality over speed.
 	// Default: quality.
-	Priority FinetunePriority `json:"priority"`
+	Priority FinetunePriority `json:"priority,omitempty"`
 	// Type of fine-tuning. 'lora' is a standard LoRA Adapter, 'full' is a full fine-tuning mode, with a post hoc lora extraction.
 	// Default: full.
-	FinetuneType FinetuneType `json:"finetune_type"`
+	FinetuneType FinetuneType `json:"finetune_type,omitempty"`
 	// Rank of the fine-tuned model. 16 or 32. If finetune_type is 'full', this will be the rank of the extracted lora model.
 	// Default: 32.
-	LoraRank LoraRank `json:"lora_rank"`
+	LoraRank LoraRank `json:"lora_rank,omitempty"`
 	// URL to receive webhook notifications.
 	// Min length: 1, Max length: 2083.
 	WebhookURL string `json:"webhook_url,omitempty"`
@@ -95,8 +190,46 @@ type FluxFinetune struct {
 	WebhookSecret string `json:"webhook_secret,omitempty"`
 }
 
+// Create a finetuning task for a base64-encoded ZIP file, with every parameter set to its documented default.
+func NewFluxFinetune(fileData string, mode FinetuneMode) *FluxFinetune {
+	return &FluxFinetune{
+		FileData:     fileData,
+		TriggerWord:  "TOK",
+		Mode:         mode,
+		Iterations:   300,
+		Captioning:   Ptr(true),
+		Priority:     FinetunePriorityQuality,
+		FinetuneType: FinetuneTypeFull,
+		LoraRank:     LoraRank32,
+	}
+}
+
 func (flx *FluxFinetune) FinetuneTaskMarker() {}
 
 func (f *FluxFinetune) GetActionURL(baseURL string) string {
 	return fmt.Sprintf("%s/v1/finetune", baseURL)
 }
+
+// Check the task parameters against the constraints of the API.
+func (f *FluxFinetune) Validate() error {
+	var v validator
+	v.required("file_data", f.FileData)
+	v.oneOf("mode", string(f.Mode), string(FinetuneModeGeneral), string(FinetuneModeCharacter), string(FinetuneModeStyle), string(FinetuneModeProduct))
+	if f.Iterations != 0 {
+		v.intRange("iterations", f.Iterations, 100, 1000)
+	}
+	if f.LearningRate != 0 {
+		v.floatRange("learning_rate", f.LearningRate, 0.000001, 0.005)
+	}
+	if f.Priority != "" {
+		v.oneOf("priority", string(f.Priority), string(FinetunePrioritySpeed), string(FinetunePriorityQuality), string(FinetunePriorityHighResOnly))
+	}
+	if f.FinetuneType != "" {
+		v.oneOf("finetune_type", string(f.FinetuneType), string(FinetuneTypeLora), string(FinetuneTypeFull))
+	}
+	if f.LoraRank != 0 && f.LoraRank != LoraRank16 && f.LoraRank != LoraRank32 {
+		v.add("lora_rank", "literal_error", "Input should be 16 or 32")
+	}
+	v.webhook(f.WebhookURL)
+	return v.err()
+}
@@ -2,6 +2,7 @@ package bfl
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 )
 
@@ -14,8 +15,30 @@ type GenerateResult struct {
 	Duration  float64 `json:"duration"`
 }
 
-// TODO: unimplemented
-type GenerateDetails struct{}
+// Diagnostic details reported by the API for an image generation task.
+type GenerateDetails struct {
+	// Reasons given when the request or the generated content was moderated.
+	ModerationReasons []string `json:"Moderation Reasons,omitempty"`
+	// Position of the task in the queue while it is pending.
+	QueuePosition int `json:"queue_position,omitempty"`
+	// Fields returned by the API that are not modelled above.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (d *GenerateDetails) UnmarshalJSON(data []byte) error {
+	type generateDetails GenerateDetails
+	var v generateDetails
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	extra, err := unknownFields(data, &v)
+	if err != nil {
+		return err
+	}
+	v.Extra = extra
+	*d = GenerateDetails(v)
+	return nil
+}
 
 // An async task for generating an image.
 type GenerateTask interface {
@@ -24,12 +47,13 @@ type GenerateTask interface {
 }
 
 // Submit an image generation task and poll for the result.
-func Generate(ctx context.Context, c *Client, task GenerateTask) (*GenerateResult, error) {
-	ar, err := c.AsyncRequest(ctx, task)
+func Generate(ctx context.Context, c *Client, task GenerateTask, opts ...PollOption) (*GenerateResult, error) {
+	t, err := c.SubmitGenerate(ctx, task, opts...)
 	if err != nil {
 		return nil, err
 	}
-	result, err := Poll[*GenerateResult, *GenerateDetails](ctx, c, ar, true)
+	defer t.Stop()
+	result, err := t.Wait(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -44,20 +68,20 @@ type FluxPro11Generate struct {
 	ImagePrompt string `json:"image_prompt,omitempty"`
 	// Width of the generated image in pixels. Must be a multiple of 32.
 	// Min: 256, Max: 1440, Default: 1024.
-	Width int `json:"width"`
+	Width int `json:"width,omitempty"`
 	// Height of the generated image in pixels. Must be a multiple of 32.
 	// Min: 256, Max: 1440, Default: 768.
-	Height int `json:"height"`
+	Height int `json:"height,omitempty"`
 	// Whether to perform upsampling on the prompt.
 	// If active, automatically modifies the prompt for more creative generation.
 	// Default: false.
-	PromptUpsampling bool `json:"prompt_upsampling"`
+	PromptUpsampling *bool `json:"prompt_upsampling,omitempty"`
 	// Optional seed for reproducibility.
-	Seed int `json:"seed,omitempty"`
+	Seed *int `json:"seed,omitempty"`
 	// Tolerance level for input and output moderation.
 	// Between 0 and 6, 0 being most strict, 6 being least strict.
 	// Min: 0, Max: 6, Default: 2.
-	SafetyTolerance int `json:"safety_tolerance"`
+	SafetyTolerance *int `json:"safety_tolerance,omitempty"`
 	// Output format for the generated image.
 	// Can be 'jpeg' or 'png'.
 	// Default: jpeg.
@@ -69,12 +93,39 @@ type FluxPro11Generate struct {
 	WebhookSecret string `json:"webhook_secret,omitempty"`
 }
 
+// Create an image generation task with Flux Pro 1.1, with every parameter set to its documented default.
+func NewFluxPro11Generate(prompt string) *FluxPro11Generate {
+	return &FluxPro11Generate{
+		Prompt:           prompt,
+		Width:            1024,
+		Height:           768,
+		PromptUpsampling: Ptr(false),
+		SafetyTolerance:  Ptr(2),
+		OutputFormat:     "jpeg",
+	}
+}
+
 func (flx *FluxPro11Generate) GenerateTaskMarker() {}
 
 func (flx *FluxPro11Generate) GetActionURL(baseURL string) string {
 	return fmt.Sprintf("%s/v1/flux-pro-1.1", baseURL)
 }
 
+// Check the task parameters against the constraints of the API.
+func (flx *FluxPro11Generate) Validate() error {
+	var v validator
+	if flx.Width != 0 {
+		v.dimension("width", flx.Width, 256, 1440)
+	}
+	if flx.Height != 0 {
+		v.dimension("height", flx.Height, 256, 1440)
+	}
+	v.optIntRange("safety_tolerance", flx.SafetyTolerance, 0, 6)
+	v.outputFormat(flx.OutputFormat)
+	v.webhook(flx.WebhookURL)
+	return v.err()
+}
+
 // Task parameters for generating an image with Flux Pro through the BFL API.
 type FluxProGenerate struct {
 	// Text prompt for image generation.
@@ -83,24 +134,24 @@ type FluxProGenerate struct {
 	ImagePrompt string `json:"image_prompt,omitempty"`
 	// Width of the generated image in pixels. Must be a multiple of 32.
 	// Min: 256, Max: 1440, Default: 1024.
-	Width int `json:"width"`
+	Width int `json:"width,omitempty"`
 	// Height of the generated image in pixels. Must be a multiple of 32.
 	// Min: 256, Max: 1440, Default: 768.
-	Height int `json:"height"`
+	Height int `json:"height,omitempty"`
 	// Number of steps for the image generation process.
 	// Min: 1, Max: 50, Default: 40.
 	Steps int `json:"steps,omitempty"`
 	// Whether to perform upsampling on the prompt. If active, automatically modifies the prompt for more creative generation.
 	// Default: false.
-	PromptUpsampling bool `json:"prompt_upsampling"`
+	PromptUpsampling *bool `json:"prompt_upsampling,omitempty"`
 	// Optional seed for reproducibility.
-	Seed int `json:"seed,omitempty"`
+	Seed *int `json:"seed,omitempty"`
 	// Guidance scale for image generation. High guidance scales improve prompt adherence at the cost of reduced realism.
 	// Min: 1.5, Max: 5, Default: 2.5.
 	Guidance float64 `json:"guidance,omitempty"`
 	// Tolerance level for input and output moderation. Between 0 and 6, 0 being most strict, 6 being least strict.
 	// Min: 0, Max: 6, Default: 2.
-	SafetyTolerance int `json:"safety_tolerance"`
+	SafetyTolerance *int `json:"safety_tolerance,omitempty"`
 	// Interval parameter for guidance control.
 	// Min: 1, Max: 4, Default: 2.
 	Interval float64 `json:"interval,omitempty"`
@@ -114,12 +165,51 @@ type FluxProGenerate struct {
 	WebhookSecret string `json:"webhook_secret,omitempty"`
 }
 
+// Create an image generation task with Flux Pro, with every parameter set to its documented default.
+func NewFluxProGenerate(prompt string) *FluxProGenerate {
+	return &FluxProGenerate{
+		Prompt:           prompt,
+		Width:            1024,
+		Height:           768,
+		Steps:            40,
+		PromptUpsampling: Ptr(false),
+		Guidance:         2.5,
+		SafetyTolerance:  Ptr(2),
+		Interval:         2,
+		OutputFormat:     "jpeg",
+	}
+}
+
 func (flx *FluxProGenerate) GenerateTaskMarker() {}
 
 func (flx *FluxProGenerate) GetActionURL(baseURL string) string {
 	return fmt.Sprintf("%s/v1/flux-pro", baseURL)
 }
 
+// Check the task parameters against the constraints of the API.
+func (flx *FluxProGenerate) Validate() error {
+	var v validator
+	if flx.Width != 0 {
+		v.dimension("width", flx.Width, 256, 1440)
+	}
+	if flx.Height != 0 {
+		v.dimension("height", flx.Height, 256, 1440)
+	}
+	if flx.Steps != 0 {
+		v.intRange("steps", flx.Steps, 1, 50)
+	}
+	if flx.Guidance != 0 {
+		v.floatRange("guidance", flx.Guidance, 1.5, 5)
+	}
+	v.optIntRange("safety_tolerance", flx.SafetyTolerance, 0, 6)
+	if flx.Interval != 0 {
+		v.floatRange("interval", flx.Interval, 1, 4)
+	}
+	v.outputFormat(flx.OutputFormat)
+	v.webhook(flx.WebhookURL)
+	return v.err()
+}
+
 // Task parameters for generating an image with Flux Dev through the BFL API.
 type FluxDevGenerate struct {
 	// Text prompt for image generation.
@@ -128,19 +218,19 @@ type FluxDevGenerate struct {
 	ImagePrompt string `json:"image_prompt,omitempty"`
 	// Width of the generated image in pixels. Must be a multiple of 32.
 	// Min: 256, Max: 1440, Default: 1024.
-	Width int `json:"width"`
+	Width int `json:"width,omitempty"`
 	// Height of the generated image in pixels. Must be a multiple of 32.
 	// Min: 256, Max: 1440, Default: 768.
-	Height int `json:"height"`
+	Height int `json:"height,omitempty"`
 	// Number of steps for the image generation process.
 	// Min: 1, Max: 50, Default: 28.
 	Steps int `json:"steps,omitempty"`
 	// Whether to perform upsampling on the prompt.
 	// If active, automatically modifies the prompt for more creative generation.
 	// Default: false.
-	PromptUpsampling bool `json:"prompt_upsampling"`
+	PromptUpsampling *bool `json:"prompt_upsampling,omitempty"`
 	// Optional seed for reproducibility.
-	Seed int `json:"seed,omitempty"`
+	Seed *int `json:"seed,omitempty"`
 	// Guidance scale for image generation.
 	// High guidance scales improve prompt adherence at the cost of reduced realism.
 	// Min: 1.5, Max: 5, Default: 3.
@@ -148,7 +238,7 @@ type FluxDevGenerate struct {
 	// Tolerance level for input and output moderation.
 	// Between 0 and 6, 0 being most strict, 6 being least strict.
 	// Min: 0, Max: 6, Default: 2.
-	SafetyTolerance int `json:"safety_tolerance"`
+	SafetyTolerance *int `json:"safety_tolerance,omitempty"`
 	// Output format for the generated image.
 	// Can be 'jpeg' or 'png'.
 	// Default: jpeg.
@@ -160,38 +250,74 @@ type FluxDevGenerate struct {
 	WebhookSecret string `json:"webhook_secret,omitempty"`
 }
 
+// Create an image generation task with Flux Dev, with every parameter set to its documented default.
+func NewFluxDevGenerate(prompt string) *FluxDevGenerate {
+	return &FluxDevGenerate{
+		Prompt:           prompt,
+		Width:            1024,
+		Height:           768,
+		Steps:            28,
+		PromptUpsampling: Ptr(false),
+		Guidance:         3,
+		SafetyTolerance:  Ptr(2),
+		OutputFormat:     "jpeg",
+	}
+}
+
 func (flx *FluxDevGenerate) GenerateTaskMarker() {}
 
 func (flx *FluxDevGenerate) GetActionURL(baseURL string) string {
 	return fmt.Sprintf("%s/v1/flux-dev", baseURL)
 }
 
+// Check the task parameters against the constraints of the API.
+func (flx *FluxDevGenerate) Validate() error {
+	var v validator
+	v.required("prompt", flx.Prompt)
+	if flx.Width != 0 {
+		v.dimension("width", flx.Width, 256, 1440)
+	}
+	if flx.Height != 0 {
+		v.dimension("height", flx.Height, 256, 1440)
+	}
+	if flx.Steps != 0 {
+		v.intRange("steps", flx.Steps, 1, 50)
+	}
+	if flx.Guidance != 0 {
+		v.floatRange("guidance", flx.Guidance, 1.5, 5)
+	}
+	v.optIntRange("safety_tolerance", flx.SafetyTolerance, 0, 6)
+	v.outputFormat(flx.OutputFormat)
+	v.webhook(flx.WebhookURL)
+	return v.err()
+}
+
 // Task parameters for generating an image with Flux Pro 1.1 Ultra through the BFL API.
 type FluxPro11UltraGenerate struct {
 	// The prompt to use for image generation.
 	Prompt string `json:"prompt,omitempty"`
 	// Whether to perform upsampling on the prompt. If active, automatically modifies the prompt for more creative generation.
 	// Default: false.
-	PromptUpsampling bool `json:"prompt_upsampling"`
+	PromptUpsampling *bool `json:"prompt_upsampling,omitempty"`
 	// Optional seed for reproducibility. If not provided, a random seed will be used.
-	Seed int `json:"seed,omitempty"`
+	Seed *int `json:"seed,omitempty"`
 	// Aspect ratio of the image between 21:9 and 9:21.
 	// Default: 16:9.
-	AspectRatio string `json:"aspect_ratio"`
+	AspectRatio AspectRatio `json:"aspect_ratio,omitempty"`
 	// Tolerance level for input and output moderation. Between 0 and 6, 0 being most strict, 6 being least strict.
 	// Min: 0, Max: 6, Default: 2.
-	SafetyTolerance int `json:"safety_tolerance"`
+	SafetyTolerance *int `json:"safety_tolerance,omitempty"`
 	// Output format for the generated image. Can be 'jpeg' or 'png'.
 	// Default: jpeg.
 	OutputFormat string `json:"output_format,omitempty"`
 	// Generate less processed, more natural-looking images.
 	// Default: false.
-	Raw bool `json:"raw"`
+	Raw *bool `json:"raw,omitempty"`
 	// Optional image to remix in base64 format.
 	ImagePrompt string `json:"image_prompt,omitempty"`
 	// Blend between the prompt and the image prompt.
 	// Min: 0, Max: 1, Default: 0.1.
-	ImagePromptStrength float64 `json:"image_prompt_strength"`
+	ImagePromptStrength *float64 `json:"image_prompt_strength,omitempty"`
 	// URL to receive webhook notifications.
 	// Min length: 1, Max length: 2083.
 	WebhookURL string `json:"webhook_url,omitempty"`
@@ -199,12 +325,40 @@ type FluxPro11UltraGenerate struct {
 	WebhookSecret string `json:"webhook_secret,omitempty"`
 }
 
+// Create an image generation task with Flux Pro 1.1 Ultra, with every parameter set to its documented default.
+func NewFluxPro11UltraGenerate(prompt string) *FluxPro11UltraGenerate {
+	return &FluxPro11UltraGenerate{
+		Prompt:              prompt,
+		PromptUpsampling:    Ptr(false),
+		AspectRatio:         AspectRatio16x9,
+		SafetyTolerance:     Ptr(2),
+		OutputFormat:        "jpeg",
+		Raw:                 Ptr(false),
+		ImagePromptStrength: Ptr(0.1),
+	}
+}
+
 func (flx *FluxPro11UltraGenerate) GenerateTaskMarker() {}
 
 func (flx *FluxPro11UltraGenerate) GetActionURL(baseURL string) string {
 	return fmt.Sprintf("%s/v1/flux-pro-1.1-ultra", baseURL)
 }
 
+// Check the task parameters against the constraints of the API.
+func (flx *FluxPro11UltraGenerate) Validate() error {
+	var v validator
+	if flx.AspectRatio != "" {
+		if err := flx.AspectRatio.Validate(); err != nil {
+			v.add("aspect_ratio", "value_error", "%v", err)
+		}
+	}
+	v.optIntRange("safety_tolerance", flx.SafetyTolerance, 0, 6)
+	v.optFloatRange("image_prompt_strength", flx.ImagePromptStrength, 0, 1)
+	v.outputFormat(flx.OutputFormat)
+	v.webhook(flx.WebhookURL)
+	return v.err()
+}
+
 // Task parameters for generating an image with Flux Pro 1.0 Fill through the BFL API.
 type FluxProFillGenerate struct {
 	// A Base64-encoded string representing the image you wish to modify. Can contain alpha mask if desired.
@@ -218,9 +372,9 @@ type FluxProFillGenerate struct {
 	Steps int `json:"steps,omitempty"`
 	// Whether to perform upsampling on the prompt. If active, automatically modifies the prompt for more creative generation.
 	// Default: false.
-	PromptUpsampling bool `json:"prompt_upsampling,omitempty"`
+	PromptUpsampling *bool `json:"prompt_upsampling,omitempty"`
 	// Optional seed for reproducibility.
-	Seed int `json:"seed,omitempty"`
+	Seed *int `json:"seed,omitempty"`
 	// Guidance strength for the image generation process.
 	// Min: 1.5, Max: 100, Default: 60.
 	Guidance float64 `json:"guidance,omitempty"`
@@ -229,7 +383,7 @@ type FluxProFillGenerate struct {
 	OutputFormat string `json:"output_format,omitempty"`
 	// Tolerance level for input and output moderation. Between 0 and 6, 0 being most strict, 6 being least strict.
 	// Min: 0, Max: 6, Default: 2.
-	SafetyTolerance int `json:"safety_tolerance"`
+	SafetyTolerance *int `json:"safety_tolerance,omitempty"`
 	// URL to receive webhook notifications.
 	// Min length: 1, Max length: 2083.
 	WebhookURL string `json:"webhook_url,omitempty"`
@@ -237,12 +391,41 @@ type FluxProFillGenerate struct {
 	WebhookSecret string `json:"webhook_secret,omitempty"`
 }
 
+// Create an inpainting task with Flux Pro 1.0 Fill for a base64-encoded image, with every parameter set to its documented default.
+func NewFluxProFillGenerate(image string) *FluxProFillGenerate {
+	return &FluxProFillGenerate{
+		Image:            image,
+		Steps:            50,
+		PromptUpsampling: Ptr(false),
+		Guidance:         60,
+		OutputFormat:     "jpeg",
+		SafetyTolerance:  Ptr(2),
+	}
+}
+
 func (flx *FluxProFillGenerate) GenerateTaskMarker() {}
 
 func (flx *FluxProFillGenerate) GetActionURL(baseURL string) string {
 	return fmt.Sprintf("%s/v1/flux-pro-1.0-fill", baseURL)
 }
 
+// Check the task parameters against the constraints of the API.
+func (flx *FluxProFillGenerate) Validate() error {
+	var v validator
+	v.required("image", flx.Image)
+	v.mask(flx.Image, flx.Mask)
+	if flx.Steps != 0 {
+		v.intRange("steps", flx.Steps, 15, 50)
+	}
+	if flx.Guidance != 0 {
+		v.floatRange("guidance", flx.Guidance, 1.5, 100)
+	}
+	v.optIntRange("safety_tolerance", flx.SafetyTolerance, 0, 6)
+	v.outputFormat(flx.OutputFormat)
+	v.webhook(flx.WebhookURL)
+	return v.err()
+}
+
 // Task parameters for generating an image with Flux Pro 1.0 Canny through the BFL API.
 type FluxProCannyGenerate struct {
 	// Text prompt for image generation.
@@ -253,15 +436,15 @@ type FluxProCannyGenerate struct {
 	PreprocessedImage string `json:"preprocessed_image,omitempty"`
 	// Low threshold for Canny edge detection.
 	// Min: 0, Max: 500, Default: 50.
-	CannyLowThreshold int `json:"canny_low_threshold,omitempty"`
+	CannyLowThreshold *int `json:"canny_low_threshold,omitempty"`
 	// High threshold for Canny edge detection.
 	// Min: 0, Max: 500, Default: 200.
-	CannyHighThreshold int `json:"canny_high_threshold,omitempty"`
+	CannyHighThreshold *int `json:"canny_high_threshold,omitempty"`
 	// Whether to perform upsampling on the prompt.
 	// Default: false.
-	PromptUpsampling bool `json:"prompt_upsampling,omitempty"`
+	PromptUpsampling *bool `json:"prompt_upsampling,omitempty"`
 	// Optional seed for reproducibility.
-	Seed int `json:"seed,omitempty"`
+	Seed *int `json:"seed,omitempty"`
 	// Number of steps for the image generation process.
 	// Min: 15, Max: 50, Default: 50.
 	Steps int `json:"steps,omitempty"`
@@ -273,7 +456,7 @@ type FluxProCannyGenerate struct {
 	Guidance float64 `json:"guidance,omitempty"`
 	// Tolerance level for input and output moderation. Between 0 and 6, 0 being most strict, 6 being least strict.
 	// Min: 0, Max: 6, Default: 2.
-	SafetyTolerance int `json:"safety_tolerance"`
+	SafetyTolerance *int `json:"safety_tolerance,omitempty"`
 	// URL to receive webhook notifications.
 	// Min length: 1, Max length: 2083.
 	WebhookURL string `json:"webhook_url,omitempty"`
@@ -281,12 +464,48 @@ type FluxProCannyGenerate struct {
 	WebhookSecret string `json:"webhook_secret,omitempty"`
 }
 
+// Create an image generation task with Flux Pro 1.0 Canny for a base64-encoded control image, with every parameter set to its documented default.
+func NewFluxProCannyGenerate(prompt string, controlImage string) *FluxProCannyGenerate {
+	return &FluxProCannyGenerate{
+		Prompt:             prompt,
+		ControlImage:       controlImage,
+		CannyLowThreshold:  Ptr(50),
+		CannyHighThreshold: Ptr(200),
+		PromptUpsampling:   Ptr(false),
+		Steps:              50,
+		OutputFormat:       "jpeg",
+		Guidance:           30,
+		SafetyTolerance:    Ptr(2),
+	}
+}
+
 func (flx *FluxProCannyGenerate) GenerateTaskMarker() {}
 
 func (flx *FluxProCannyGenerate) GetActionURL(baseURL string) string {
 	return fmt.Sprintf("%s/v1/flux-pro-1.0-canny", baseURL)
 }
 
+// Check the task parameters against the constraints of the API.
+func (flx *FluxProCannyGenerate) Validate() error {
+	var v validator
+	v.required("prompt", flx.Prompt)
+	if flx.ControlImage == "" && flx.PreprocessedImage == "" {
+		v.add("control_image", "missing", "Either control_image or preprocessed_image is required")
+	}
+	v.optIntRange("canny_low_threshold", flx.CannyLowThreshold, 0, 500)
+	v.optIntRange("canny_high_threshold", flx.CannyHighThreshold, 0, 500)
+	if flx.Steps != 0 {
+		v.intRange("steps", flx.Steps, 15, 50)
+	}
+	if flx.Guidance != 0 {
+		v.floatRange("guidance", flx.Guidance, 1, 100)
+	}
+	v.optIntRange("safety_tolerance", flx.SafetyTolerance, 0, 6)
+	v.outputFormat(flx.OutputFormat)
+	v.webhook(flx.WebhookURL)
+	return v.err()
+}
+
 // Task parameters for generating an image with Flux Pro 1.0 Depth through the BFL API.
 type FluxProDepthGenerate struct {
 	// Text prompt for image generation.
@@ -297,9 +516,9 @@ type FluxProDepthGenerate struct {
 	PreprocessedImage string `json:"preprocessed_image,omitempty"`
 	// Whether to perform upsampling on the prompt.
 	// Default: false.
-	PromptUpsampling bool `json:"prompt_upsampling,omitempty"`
+	PromptUpsampling *bool `json:"prompt_upsampling,omitempty"`
 	// Optional seed for reproducibility.
-	Seed int `json:"seed,omitempty"`
+	Seed *int `json:"seed,omitempty"`
 	// Number of steps for the image generation process.
 	// Min: 15, Max: 50, Default: 50.
 	Steps int `json:"steps,omitempty"`
@@ -311,7 +530,7 @@ type FluxProDepthGenerate struct {
 	Guidance float64 `json:"guidance,omitempty"`
 	// Tolerance level for input and output moderation. Between 0 and 6, 0 being most strict, 6 being least strict.
 	// Min: 0, Max: 6, Default: 2.
-	SafetyTolerance int `json:"safety_tolerance"`
+	SafetyTolerance *int `json:"safety_tolerance,omitempty"`
 	// URL to receive webhook notifications.
 	// Min length: 1, Max length: 2083.
 	WebhookURL string `json:"webhook_url,omitempty"`
@@ -319,46 +538,78 @@ type FluxProDepthGenerate struct {
 	WebhookSecret string `json:"webhook_secret,omitempty"`
 }
 
+// Create an image generation task with Flux Pro 1.0 Depth for a base64-encoded control image, with every parameter set to its documented default.
+func NewFluxProDepthGenerate(prompt string, controlImage string) *FluxProDepthGenerate {
+	return &FluxProDepthGenerate{
+		Prompt:           prompt,
+		ControlImage:     controlImage,
+		PromptUpsampling: Ptr(false),
+		Steps:            50,
+		OutputFormat:     "jpeg",
+		Guidance:         15,
+		SafetyTolerance:  Ptr(2),
+	}
+}
+
 func (flx *FluxProDepthGenerate) GenerateTaskMarker() {}
 
 func (flx *FluxProDepthGenerate) GetActionURL(baseURL string) string {
 	return fmt.Sprintf("%s/v1/flux-pro-1.0-depth", baseURL)
 }
 
+// Check the task parameters against the constraints of the API.
+func (flx *FluxProDepthGenerate) Validate() error {
+	var v validator
+	v.required("prompt", flx.Prompt)
+	if flx.ControlImage == "" && flx.PreprocessedImage == "" {
+		v.add("control_image", "missing", "Either control_image or preprocessed_image is required")
+	}
+	if flx.Steps != 0 {
+		v.intRange("steps", flx.Steps, 15, 50)
+	}
+	if flx.Guidance != 0 {
+		v.floatRange("guidance", flx.Guidance, 1, 100)
+	}
+	v.optIntRange("safety_tolerance", flx.SafetyTolerance, 0, 6)
+	v.outputFormat(flx.OutputFormat)
+	v.webhook(flx.WebhookURL)
+	return v.err()
+}
+
 // Task parameters for generating an image with Flux Pro Finetuned through the BFL API.
 type FluxProFinetunedGenerate struct {
 	// ID of the fine-tuned model you want to use.
 	FinetuneID string `json:"finetune_id"`
 	// Strength of the fine-tuned model. 0.0 means no influence, 1.0 means full influence. Allowed values up to 2.0.
 	// Min: 0, Max: 2, Default: 1.1.
-	FinetuneStrength float64 `json:"finetune_strength"`
+	FinetuneStrength *float64 `json:"finetune_strength,omitempty"`
 	// Number of steps for the fine-tuning process.
 	// Min: 1, Max: 50, Default: 40.
-	Steps int `json:"steps"`
+	Steps int `json:"steps,omitempty"`
 	// Guidance scale for image generation. High guidance scales improve prompt adherence at the cost of reduced realism.
 	// Min: 1.5, Max: 5, Default: 2.5.
-	Guidance float64 `json:"guidance"`
+	Guidance float64 `json:"guidance,omitempty"`
 	// Text prompt for image generation.
 	Prompt string `json:"prompt"`
 	// Optional base64 encoded image to use with Flux Redux.
 	ImagePrompt string `json:"image_prompt,omitempty"`
 	// Width of the generated image in pixels. Must be a multiple of 32.
 	// Min: 256, Max: 1440, Default: 1024.
-	Width int `json:"width"`
+	Width int `json:"width,omitempty"`
 	// Height of the generated image in pixels. Must be a multiple of 32.
 	// Min: 256, Max: 1440, Default: 768.
-	Height int `json:"height"`
+	Height int `json:"height,omitempty"`
 	// Whether to perform upsampling on the prompt. If active, automatically modifies the prompt for more creative generation.
 	// Default: false.
-	PromptUpsampling bool `json:"prompt_upsampling"`
+	PromptUpsampling *bool `json:"prompt_upsampling,omitempty"`
 	// Optional seed for reproducibility.
-	Seed int `json:"seed,omitempty"`
+	Seed *int `json:"seed,omitempty"`
 	// Tolerance level for input and output moderation. Between 0 and 6, 0 being most strict, 6 being least strict.
 	// Min: 0, Max: 6, Default: 2.
-	SafetyTolerance int `json:"safety_tolerance"`
+	SafetyTolerance *int `json:"safety_tolerance,omitempty"`
 	// Output format for the generated image. Can be 'jpeg' or 'png'.
 	// Default: jpeg.
-	OutputFormat string `json:"output_format"`
+	OutputFormat string `json:"output_format,omitempty"`
 	// URL to receive webhook notifications.
 	// Min length: 1, Max length: 2083.
 	WebhookURL string `json:"webhook_url,omitempty"`
@@ -366,40 +617,80 @@ type FluxProFinetunedGenerate struct {
 	WebhookSecret string `json:"webhook_secret,omitempty"`
 }
 
+// Create an image generation task with a model fine-tuned from Flux Pro, with every parameter set to its documented default.
+func NewFluxProFinetunedGenerate(finetuneID string, prompt string) *FluxProFinetunedGenerate {
+	return &FluxProFinetunedGenerate{
+		FinetuneID:       finetuneID,
+		FinetuneStrength: Ptr(1.1),
+		Steps:            40,
+		Guidance:         2.5,
+		Prompt:           prompt,
+		Width:            1024,
+		Height:           768,
+		PromptUpsampling: Ptr(false),
+		SafetyTolerance:  Ptr(2),
+		OutputFormat:     "jpeg",
+	}
+}
+
 func (flx *FluxProFinetunedGenerate) GenerateTaskMarker() {}
 
 func (flx *FluxProFinetunedGenerate) GetActionURL(baseURL string) string {
 	return fmt.Sprintf("%s/v1/flux-pro-finetuned", baseURL)
 }
 
+// Check the task parameters against the constraints of the API.
+func (flx *FluxProFinetunedGenerate) Validate() error {
+	var v validator
+	v.required("finetune_id", flx.FinetuneID)
+	v.optFloatRange("finetune_strength", flx.FinetuneStrength, 0, 2)
+	if flx.Steps != 0 {
+		v.intRange("steps", flx.Steps, 1, 50)
+	}
+	if flx.Guidance != 0 {
+		v.floatRange("guidance", flx.Guidance, 1.5, 5)
+	}
+	v.required("prompt", flx.Prompt)
+	if flx.Width != 0 {
+		v.dimension("width", flx.Width, 256, 1440)
+	}
+	if flx.Height != 0 {
+		v.dimension("height", flx.Height, 256, 1440)
+	}
+	v.optIntRange("safety_tolerance", flx.SafetyTolerance, 0, 6)
+	v.outputFormat(flx.OutputFormat)
+	v.webhook(flx.WebhookURL)
+	return v.err()
+}
+
 // Task parameters for generating an image with Flux Pro 1.0 Depth Finetuned through the BFL API.
 type FluxProDepthFinetunedGenerate struct {
 	// ID of the fine-tuned model you want to use.
 	FinetuneID string `json:"finetune_id"`
 	// Strength of the fine-tuned model. 0.0 means no influence, 1.0 means full influence. Allowed values up to 2.0.
 	// Min: 0, Max: 2, Default: 1.1.
-	FinetuneStrength float64 `json:"finetune_strength"`
+	FinetuneStrength *float64 `json:"finetune_strength,omitempty"`
 	// Text prompt for image generation.
 	Prompt string `json:"prompt"`
 	// Base64 encoded image to use as control input.
 	ControlImage string `json:"control_image"`
 	// Whether to perform upsampling on the prompt.
 	// Default: false.
-	PromptUpsampling bool `json:"prompt_upsampling"`
+	PromptUpsampling *bool `json:"prompt_upsampling,omitempty"`
 	// Optional seed for reproducibility.
-	Seed int `json:"seed,omitempty"`
+	Seed *int `json:"seed,omitempty"`
 	// Number of steps for the image generation process.
 	// Min: 15, Max: 50, Default: 50.
-	Steps int `json:"steps"`
+	Steps int `json:"steps,omitempty"`
 	// Output format for the generated image. Can be 'jpeg' or 'png'.
 	// Default: jpeg.
 	OutputFormat string `json:"output_format,omitempty"`
 	// Guidance strength for the image generation process.
 	// Min: 1, Max: 100, Default: 15.
-	Guidance float64 `json:"guidance"`
+	Guidance float64 `json:"guidance,omitempty"`
 	// Tolerance level for input and output moderation. Between 0 and 6, 0 being most strict, 6 being least strict.
 	// Min: 0, Max: 6, Default: 2.
-	SafetyTolerance int `json:"safety_tolerance"`
+	SafetyTolerance *int `json:"safety_tolerance,omitempty"`
 	// URL to receive webhook notifications.
 	// Min length: 1, Max length: 2083.
 	WebhookURL string `json:"webhook_url,omitempty"`
@@ -407,19 +698,53 @@ type FluxProDepthFinetunedGenerate struct {
 	WebhookSecret string `json:"webhook_secret,omitempty"`
 }
 
+// Create an image generation task with a model fine-tuned from Flux Pro 1.0 Depth, with every parameter set to its documented default.
+func NewFluxProDepthFinetunedGenerate(finetuneID string, prompt string, controlImage string) *FluxProDepthFinetunedGenerate {
+	return &FluxProDepthFinetunedGenerate{
+		FinetuneID:       finetuneID,
+		FinetuneStrength: Ptr(1.1),
+		Prompt:           prompt,
+		ControlImage:     controlImage,
+		PromptUpsampling: Ptr(false),
+		Steps:            50,
+		OutputFormat:     "jpeg",
+		Guidance:         15,
+		SafetyTolerance:  Ptr(2),
+	}
+}
+
 func (flx *FluxProDepthFinetunedGenerate) GenerateTaskMarker() {}
 
 func (flx *FluxProDepthFinetunedGenerate) GetActionURL(baseURL string) string {
 	return fmt.Sprintf("%s/v1/flux-pro-1.0-depth-finetuned", baseURL)
 }
 
+// Check the task parameters against the constraints of the API.
+func (flx *FluxProDepthFinetunedGenerate) Validate() error {
+	var v validator
+	v.required("finetune_id", flx.FinetuneID)
+	v.optFloatRange("finetune_strength", flx.FinetuneStrength, 0, 2)
+	v.required("prompt", flx.Prompt)
+	v.required("control_image", flx.ControlImage)
+	if flx.Steps != 0 {
+		v.intRange("steps", flx.Steps, 15, 50)
+	}
+	if flx.Guidance != 0 {
+		v.floatRange("guidance", flx.Guidance, 1, 100)
+	}
+	v.optIntRange("safety_tolerance", flx.SafetyTolerance, 0, 6)
+	v.outputFormat(flx.OutputFormat)
+	v.webhook(flx.WebhookURL)
+	return v.err()
+}
+
 // Task parameters for generating an image with Flux Pro 1.0 Canny Finetuned through the BFL API.
 type FluxProCannyFinetunedGenerate struct {
 	// ID of the fine-tuned model you want to use.
 	FinetuneID string `json:"finetune_id"`
 	// Strength of the fine-tuned model. 0.0 means no influence, 1.0 means full influence. Allowed values up to 2.0.
 	// Min: 0, Max: 2, Default: 1.1.
-	FinetuneStrength float64 `json:"finetune_strength"`
+	FinetuneStrength *float64 `json:"finetune_strength,omitempty"`
 	// Text prompt for image generation.
 	Prompt string `json:"prompt"`
 	// Base64 encoded image to use as control input if no preprocessed image is provided.
@@ -428,15 +753,15 @@ type FluxProCannyFinetunedGenerate struct {
 	PreprocessedImage string `json:"preprocessed_image,omitempty"`
 	// Low threshold for Canny edge detection.
 	// Min: 0, Max: 500, Default: 50.
-	CannyLowThreshold int `json:"canny_low_threshold,omitempty"`
+	CannyLowThreshold *int `json:"canny_low_threshold,omitempty"`
 	// High threshold for Canny edge detection.
 	// Min: 0, Max: 500, Default: 200.
-	CannyHighThreshold int `json:"canny_high_threshold,omitempty"`
+	CannyHighThreshold *int `json:"canny_high_threshold,omitempty"`
 	// Whether to perform upsampling on the prompt.
 	// Default: false.
-	PromptUpsampling bool `json:"prompt_upsampling,omitempty"`
+	PromptUpsampling *bool `json:"prompt_upsampling,omitempty"`
 	// Optional seed for reproducibility.
-	Seed int `json:"seed,omitempty"`
+	Seed *int `json:"seed,omitempty"`
 	// Number of steps for the image generation process.
 	// Min: 15, Max: 50, Default: 50.
 	Steps int `json:"steps,omitempty"`
@@ -448,7 +773,7 @@ type FluxProCannyFinetunedGenerate struct {
 	Guidance float64 `json:"guidance,omitempty"`
 	// Tolerance level for input and output moderation. Between 0 and 6, 0 being most strict, 6 being least strict.
 	// Min: 0, Max: 6, Default: 2.
-	SafetyTolerance int `json:"safety_tolerance"`
+	SafetyTolerance *int `json:"safety_tolerance,omitempty"`
 	// URL to receive webhook notifications.
 	// Min length: 1, Max length: 2083.
 	WebhookURL string `json:"webhook_url,omitempty"`
@@ -456,19 +781,59 @@ type FluxProCannyFinetunedGenerate struct {
 	WebhookSecret string `json:"webhook_secret,omitempty"`
 }
 
+// Create an image generation task with a model fine-tuned from Flux Pro 1.0 Canny, with every parameter set to its documented default.
+func NewFluxProCannyFinetunedGenerate(finetuneID string, prompt string, controlImage string) *FluxProCannyFinetunedGenerate {
+	return &FluxProCannyFinetunedGenerate{
+		FinetuneID:         finetuneID,
+		FinetuneStrength:   Ptr(1.1),
+		Prompt:             prompt,
+		ControlImage:       controlImage,
+		CannyLowThreshold:  Ptr(50),
+		CannyHighThreshold: Ptr(200),
+		PromptUpsampling:   Ptr(false),
+		Steps:              50,
+		OutputFormat:       "jpeg",
+		Guidance:           30,
+		SafetyTolerance:    Ptr(2),
+	}
+}
+
 func (flx *FluxProCannyFinetunedGenerate) GenerateTaskMarker() {}
 
 func (flx *FluxProCannyFinetunedGenerate) GetActionURL(baseURL string) string {
 	return fmt.Sprintf("%s/v1/flux-pro-1.0-canny-finetuned", baseURL)
 }
 
+// Check the task parameters against the constraints of the API.
+func (flx *FluxProCannyFinetunedGenerate) Validate() error {
+	var v validator
+	v.required("finetune_id", flx.FinetuneID)
+	v.optFloatRange("finetune_strength", flx.FinetuneStrength, 0, 2)
+	v.required("prompt", flx.Prompt)
+	if flx.ControlImage == "" && flx.PreprocessedImage == "" {
+		v.add("control_image", "missing", "Either control_image or preprocessed_image is required")
+	}
+	v.optIntRange("canny_low_threshold", flx.CannyLowThreshold, 0, 500)
+	v.optIntRange("canny_high_threshold", flx.CannyHighThreshold, 0, 500)
+	if flx.Steps != 0 {
+		v.intRange("steps", flx.Steps, 15, 50)
+	}
+	if flx.Guidance != 0 {
+		v.floatRange("guidance", flx.Guidance, 1, 100)
+	}
+	v.optIntRange("safety_tolerance", flx.SafetyTolerance, 0, 6)
+	v.outputFormat(flx.OutputFormat)
+	v.webhook(flx.WebhookURL)
+	return v.err()
+}
+
 // Task parameters for generating an image with Flux Pro 1.0 Fill Finetuned through the BFL API.
 type FluxProFillFinetunedGenerate struct {
 	// ID of the fine-tuned model you want to use.
 	FinetuneID string `json:"finetune_id"`
 	// Strength of the fine-tuned model. 0.0 means no influence, 1.0 means full influence. Allowed values up to 2.0.
 	// Min: 0, Max: 2, Default: 1.1.
-	FinetuneStrength float64 `json:"finetune_strength"`
+	FinetuneStrength *float64 `json:"finetune_strength,omitempty"`
 	// A Base64-encoded string representing the image you wish to modify. Can contain alpha mask if desired.
 	Image string `json:"image"`
 	// A Base64-encoded string representing a mask for the areas you want to modify in the image. The mask should be the same dimensions as the image and in black and white. Black areas (0%) indicate no modification, while white areas (100%) specify areas for inpainting. Optional if you provide an alpha mask in the original image.
@@ -480,9 +845,9 @@ type FluxProFillFinetunedGenerate struct {
 	Steps int `json:"steps,omitempty"`
 	// Whether to perform upsampling on the prompt. If active, automatically modifies the prompt for more creative generation.
 	// Default: false.
-	PromptUpsampling bool `json:"prompt_upsampling,omitempty"`
+	PromptUpsampling *bool `json:"prompt_upsampling,omitempty"`
 	// Optional seed for reproducibility.
-	Seed int `json:"seed,omitempty"`
+	Seed *int `json:"seed,omitempty"`
 	// Guidance strength for the image generation process.
 	// Min: 1.5, Max: 100, Default: 60.
 	Guidance float64 `json:"guidance,omitempty"`
@@ -491,7 +856,7 @@ type FluxProFillFinetunedGenerate struct {
 	OutputFormat string `json:"output_format,omitempty"`
 	// Tolerance level for input and output moderation. Between 0 and 6, 0 being most strict, 6 being least strict.
 	// Min: 0, Max: 6, Default: 2.
-	SafetyTolerance int `json:"safety_tolerance"`
+	SafetyTolerance *int `json:"safety_tolerance,omitempty"`
 	// URL to receive webhook notifications.
 	// Min length: 1, Max length: 2083.
 	WebhookURL string `json:"webhook_url,omitempty"`
@@ -499,43 +864,76 @@ type FluxProFillFinetunedGenerate struct {
 	WebhookSecret string `json:"webhook_secret,omitempty"`
 }
 
+// Create an inpainting task with a model fine-tuned from Flux Pro 1.0 Fill, with every parameter set to its documented default.
+func NewFluxProFillFinetunedGenerate(finetuneID string, image string) *FluxProFillFinetunedGenerate {
+	return &FluxProFillFinetunedGenerate{
+		FinetuneID:       finetuneID,
+		FinetuneStrength: Ptr(1.1),
+		Image:            image,
+		Steps:            50,
+		PromptUpsampling: Ptr(false),
+		Guidance:         60,
+		OutputFormat:     "jpeg",
+		SafetyTolerance:  Ptr(2),
+	}
+}
+
 func (flx *FluxProFillFinetunedGenerate) GenerateTaskMarker() {}
 
 func (flx *FluxProFillFinetunedGenerate) GetActionURL(baseURL string) string {
 	return fmt.Sprintf("%s/v1/flux-pro-1.0-fill-finetuned", baseURL)
 }
 
+// Check the task parameters against the constraints of the API.
+func (flx *FluxProFillFinetunedGenerate) Validate() error {
+	var v validator
+	v.required("finetune_id", flx.FinetuneID)
+	v.optFloatRange("finetune_strength", flx.FinetuneStrength, 0, 2)
+	v.required("image", flx.Image)
+	v.mask(flx.Image, flx.Mask)
+	if flx.Steps != 0 {
+		v.intRange("steps", flx.Steps, 15, 50)
+	}
+	if flx.Guidance != 0 {
+		v.floatRange("guidance", flx.Guidance, 1.5, 100)
+	}
+	v.optIntRange("safety_tolerance", flx.SafetyTolerance, 0, 6)
+	v.outputFormat(flx.OutputFormat)
+	v.webhook(flx.WebhookURL)
+	return v.err()
+}
+
 // Task parameters for generating an image with Flux Pro 1.1 Ultra Finetuned through the BFL API.
 type FluxPro11UltraFinetunedGenerate struct {
 	// ID of the fine-tuned model you want to use.
 	FinetuneID string `json:"finetune_id"`
 	// Strength of the fine-tuned model. 0.0 means no influence, 1.0 means full influence. Allowed values up to 2.0.
 	// Min: 0, Max: 2, Default: 1.1.
-	FinetuneStrength float64 `json:"finetune_strength"`
+	FinetuneStrength *float64 `json:"finetune_strength,omitempty"`
 	// The prompt to use for image generation.
 	Prompt string `json:"prompt,omitempty"`
 	// Whether to perform upsampling on the prompt. If active, automatically modifies the prompt for more creative generation.
 	// Default: false.
-	PromptUpsampling bool `json:"prompt_upsampling"`
+	PromptUpsampling *bool `json:"prompt_upsampling,omitempty"`
 	// Optional seed for reproducibility. If not provided, a random seed will be used.
-	Seed int `json:"seed,omitempty"`
+	Seed *int `json:"seed,omitempty"`
 	// Aspect ratio of the image between 21:9 and 9:21.
 	// Default: 16:9.
-	AspectRatio string `json:"aspect_ratio"`
+	AspectRatio AspectRatio `json:"aspect_ratio,omitempty"`
 	// Tolerance level for input and output moderation. Between 0 and 6, 0 being most strict, 6 being least strict.
 	// Min: 0, Max: 6, Default: 2.
-	SafetyTolerance int `json:"safety_tolerance"`
+	SafetyTolerance *int `json:"safety_tolerance,omitempty"`
 	// Output format for the generated image. Can be 'jpeg' or 'png'.
 	// Default: jpeg.
 	OutputFormat string `json:"output_format,omitempty"`
 	// Generate less processed, more natural-looking images.
 	// Default: false.
-	Raw bool `json:"raw"`
+	Raw *bool `json:"raw,omitempty"`
 	// Optional image to remix in base64 format.
 	ImagePrompt string `json:"image_prompt,omitempty"`
 	// Blend between the prompt and the image prompt.
 	// Min: 0, Max: 1, Default: 0.1.
-	ImagePromptStrength float64 `json:"image_prompt_strength"`
+	ImagePromptStrength *float64 `json:"image_prompt_strength,omitempty"`
 	// URL to receive webhook notifications.
 	// Min length: 1, Max length: 2083.
 	WebhookURL string `json:"webhook_url,omitempty"`
@@ -543,8 +941,40 @@ type FluxPro11UltraFinetunedGenerate struct {
 	WebhookSecret string `json:"webhook_secret,omitempty"`
 }
 
+// Create an image generation task with a model fine-tuned from Flux Pro 1.1 Ultra, with every parameter set to its documented default.
+func NewFluxPro11UltraFinetunedGenerate(finetuneID string, prompt string) *FluxPro11UltraFinetunedGenerate {
+	return &FluxPro11UltraFinetunedGenerate{
+		FinetuneID:          finetuneID,
+		FinetuneStrength:    Ptr(1.1),
+		Prompt:              prompt,
+		PromptUpsampling:    Ptr(false),
+		AspectRatio:         AspectRatio16x9,
+		SafetyTolerance:     Ptr(2),
+		OutputFormat:        "jpeg",
+		Raw:                 Ptr(false),
+		ImagePromptStrength: Ptr(0.1),
+	}
+}
+
 func (flx *FluxPro11UltraFinetunedGenerate) GenerateTaskMarker() {}
 
 func (flx *FluxPro11UltraFinetunedGenerate) GetActionURL(baseURL string) string {
 	return fmt.Sprintf("%s/v1/flux-pro-1.1-ultra-finetuned", baseURL)
 }
+
+// Check the task parameters against the constraints of the API.
+func (flx *FluxPro11UltraFinetunedGenerate) Validate() error {
+	var v validator
+	if flx.AspectRatio != "" {
+		if err := flx.AspectRatio.Validate(); err != nil {
+			v.add("aspect_ratio", "value_error", "%v", err)
+		}
+	}
+	v.required("finetune_id", flx.FinetuneID)
+	v.optFloatRange("finetune_strength", flx.FinetuneStrength, 0, 2)
+	v.optIntRange("safety_tolerance", flx.SafetyTolerance, 0, 6)
+	v.optFloatRange("image_prompt_strength", flx.ImagePromptStrength, 0, 1)
+	v.outputFormat(flx.OutputFormat)
+	v.webhook(flx.WebhookURL)
+	return v.err()
+}
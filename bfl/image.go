@@ -0,0 +1,224 @@
+package bfl
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+
+	"github.com/Kodlak15/bfl-go/internal/imageutil"
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/webp"
+)
+
+// Default limit on the size of an image fetched from a URL.
+const defaultMaxImageBytes = 50 << 20
+
+// An image to send to the API, e.g. as ImagePrompt, Image, Mask or ControlImage.
+// Images are loaded lazily when encoded. JPEG, PNG, GIF, WebP and BMP images are read, and
+// encoded as JPEG or PNG, the formats accepted by the API.
+type ImageInput struct {
+	path string
+	r    io.Reader
+	data []byte
+	img  image.Image
+	url  string
+}
+
+// An image read from a file.
+func ImageFromPath(path string) *ImageInput {
+	return &ImageInput{path: path}
+}
+
+// An image read from a reader, which is consumed on first use.
+func ImageFromReader(r io.Reader) *ImageInput {
+	return &ImageInput{r: r}
+}
+
+// An image from encoded data in any of the supported formats.
+func ImageFromBytes(data []byte) *ImageInput {
+	return &ImageInput{data: data}
+}
+
+// An image from a decoded image.
+func ImageFromImage(img image.Image) *ImageInput {
+	return &ImageInput{img: img}
+}
+
+// An image fetched from a URL through the client passed when encoding it.
+func ImageFromURL(url string) *ImageInput {
+	return &ImageInput{url: url}
+}
+
+type imageOptions struct {
+	format    string
+	maxSide   int
+	maxPixels int
+	quality   int
+}
+
+// An option for configuring how an image is encoded.
+type ImageOption func(o *imageOptions)
+
+// Encode the image as "jpeg" or "png". By default JPEG and PNG images keep their format, and
+// images of other formats become PNG if they may be transparent, JPEG otherwise.
+func ImageFormat(format string) ImageOption {
+	return func(o *imageOptions) {
+		o.format = format
+	}
+}
+
+// Downsize the image, keeping its aspect ratio, so that neither side exceeds the given length.
+func ImageMaxSide(pixels int) ImageOption {
+	return func(o *imageOptions) {
+		o.maxSide = pixels
+	}
+}
+
+// Downsize the image, keeping its aspect ratio, so that its area does not exceed the given number of pixels.
+func ImageMaxPixels(pixels int) ImageOption {
+	return func(o *imageOptions) {
+		o.maxPixels = pixels
+	}
+}
+
+// Downsize the image so that neither side exceeds 1440 pixels, the largest width and height
+// generated by the API.
+func ImageAPILimits() ImageOption {
+	return ImageMaxSide(MaxDimension)
+}
+
+// Quality of JPEG encoding, between 1 and 100.
+// Default: 95.
+func ImageJPEGQuality(quality int) ImageOption {
+	return func(o *imageOptions) {
+		o.quality = quality
+	}
+}
+
+// Read the encoded image, fetching it if needed. Returns nil data for decoded images.
+func (in *ImageInput) load(ctx context.Context, c *Client) ([]byte, error) {
+	switch {
+	case in.data != nil:
+		return in.data, nil
+	case in.path != "":
+		data, err := os.ReadFile(in.path)
+		if err != nil {
+			return nil, err
+		}
+		in.data = data
+	case in.r != nil:
+		data, err := io.ReadAll(in.r)
+		if err != nil {
+			return nil, err
+		}
+		in.data = data
+	case in.url != "":
+		if c == nil {
+			c = &Client{}
+		}
+		data, err := c.download(ctx, in.url, defaultMaxImageBytes)
+		if err != nil {
+			return nil, err
+		}
+		in.data = data
+	case in.img == nil:
+		return nil, fmt.Errorf("empty image input")
+	}
+	return in.data, nil
+}
+
+// Decode the image. The client is only used to fetch images from URLs and may be nil.
+func (in *ImageInput) Image(ctx context.Context, c *Client) (image.Image, error) {
+	if in.img != nil {
+		return in.img, nil
+	}
+	data, err := in.load(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	in.img = img
+	return img, nil
+}
+
+// Encode the image in a format accepted by the API, downsizing it if requested.
+// Images that already satisfy the options are passed through unchanged.
+// The client is only used to fetch images from URLs and may be nil.
+func (in *ImageInput) Encode(ctx context.Context, c *Client, opts ...ImageOption) ([]byte, error) {
+	o := imageOptions{quality: 95}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	data, err := in.load(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	sourceFormat := ""
+	if data != nil {
+		cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		sourceFormat = format
+		w, h := imageutil.FitWithin(cfg.Width, cfg.Height, o.maxSide, o.maxPixels)
+		resized := w != cfg.Width || h != cfg.Height
+		if !resized && (o.format == "" && (format == "jpeg" || format == "png") || o.format == format) {
+			return data, nil
+		}
+	}
+	img, err := in.Image(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	b := img.Bounds()
+	if w, h := imageutil.FitWithin(b.Dx(), b.Dy(), o.maxSide, o.maxPixels); w != b.Dx() || h != b.Dy() {
+		img = imageutil.Resize(img, w, h)
+	}
+	format := o.format
+	if format == "" {
+		format = "jpeg"
+		if sourceFormat == "png" || sourceFormat != "jpeg" && hasAlpha(img) {
+			format = "png"
+		}
+	}
+	var buf bytes.Buffer
+	switch format {
+	case "png":
+		err = png.Encode(&buf, img)
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: o.quality})
+	default:
+		return nil, fmt.Errorf("unsupported image format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Encode the image as base64, the form expected by the image fields of the tasks. See Encode.
+func (in *ImageInput) Base64(ctx context.Context, c *Client, opts ...ImageOption) (string, error) {
+	data, err := in.Encode(ctx, c, opts...)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// Whether the image may contain transparent pixels.
+func hasAlpha(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return !o.Opaque()
+	}
+	return true
+}
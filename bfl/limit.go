@@ -0,0 +1,122 @@
+package bfl
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// A token bucket limiting the rate at which tasks are submitted.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(perSecond float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:   perSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Block until a token is available or the context is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+		l.last = now
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		l.mu.Unlock()
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Caps the number of tasks that are in flight at the same time.
+type taskLimiter struct {
+	slots  chan struct{}
+	mu     sync.Mutex
+	active map[string]struct{}
+}
+
+func newTaskLimiter(n int) *taskLimiter {
+	return &taskLimiter{
+		slots:  make(chan struct{}, n),
+		active: make(map[string]struct{}),
+	}
+}
+
+func (l *taskLimiter) acquire(ctx context.Context) error {
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Give back a slot that was acquired but not bound to a task.
+func (l *taskLimiter) abandon() {
+	<-l.slots
+}
+
+// Bind an acquired slot to a submitted task.
+func (l *taskLimiter) bind(taskID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.active[taskID] = struct{}{}
+}
+
+func (l *taskLimiter) release(taskID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.active[taskID]; !ok {
+		return
+	}
+	delete(l.active, taskID)
+	<-l.slots
+}
+
+func (l *taskLimiter) count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.active)
+}
+
+// Release the concurrency slot held by a task.
+// Slots are released automatically when Poll returns and when GetResult observes a terminal
+// status, so this is only needed for tasks that are abandoned without ever being polled.
+func (c *Client) ReleaseTask(taskID string) {
+	if c.tasks != nil {
+		c.tasks.release(taskID)
+	}
+}
+
+// The number of submitted tasks that have not reached a terminal status yet.
+// Always zero unless the client was created with WithMaxActiveTasks.
+func (c *Client) ActiveTasks() int {
+	if c.tasks == nil {
+		return 0
+	}
+	return c.tasks.count()
+}
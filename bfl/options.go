@@ -0,0 +1,102 @@
+package bfl
+
+import (
+	"net/http"
+	"time"
+)
+
+// An option for configuring a Client created with NewClientWithOptions.
+type ClientOption func(c *Client)
+
+// Use the given HTTP client for all requests, e.g. to configure proxies, TLS roots or a test transport.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.HTTPClient = httpClient
+	}
+}
+
+// Send the given user agent with every request.
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *Client) {
+		c.UserAgent = userAgent
+	}
+}
+
+// Send the given header with every request. May be used multiple times.
+func WithHeader(name string, value string) ClientOption {
+	return func(c *Client) {
+		if c.Headers == nil {
+			c.Headers = make(http.Header)
+		}
+		c.Headers.Add(name, value)
+	}
+}
+
+// Send all of the given headers with every request.
+func WithHeaders(headers http.Header) ClientOption {
+	return func(c *Client) {
+		if c.Headers == nil {
+			c.Headers = make(http.Header)
+		}
+		for name, values := range headers {
+			for _, value := range values {
+				c.Headers.Add(name, value)
+			}
+		}
+	}
+}
+
+// Limit the duration of each individual HTTP call made by the client.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.Timeout = timeout
+	}
+}
+
+// Retry transient failures according to the given policy.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.RetryPolicy = &policy
+	}
+}
+
+// Limit task submissions to the given rate, allowing bursts of up to burst submissions.
+func WithRateLimit(perSecond float64, burst int) ClientOption {
+	return func(c *Client) {
+		if perSecond > 0 {
+			c.limiter = newRateLimiter(perSecond, burst)
+		}
+	}
+}
+
+// Cap the number of tasks in flight at the same time. Submissions block until a slot is free.
+// A slot is released once polling observes a terminal status for its task, or by ReleaseTask.
+func WithMaxActiveTasks(n int) ClientOption {
+	return func(c *Client) {
+		if n > 0 {
+			c.tasks = newTaskLimiter(n)
+		}
+	}
+}
+
+// Use the given strategy for polling unless another one is passed to Poll.
+func WithPollStrategy(strategy PollStrategy) ClientOption {
+	return func(c *Client) {
+		c.PollStrategy = strategy
+	}
+}
+
+// Give up polling a task after the given duration unless another limit is passed to Poll.
+func WithMaxPollWait(maxWait time.Duration) ClientOption {
+	return func(c *Client) {
+		c.MaxPollWait = maxWait
+	}
+}
+
+// Refuse to submit tasks while the credit balance is below the given threshold.
+// The balance is fetched before submitting and reused for up to 30 seconds, until a task is submitted.
+func WithMinCredits(threshold float64) ClientOption {
+	return func(c *Client) {
+		c.MinCredits = threshold
+	}
+}
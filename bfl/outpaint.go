@@ -0,0 +1,186 @@
+package bfl
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/draw"
+
+	"github.com/Kodlak15/bfl-go/internal/imageutil"
+	"github.com/Kodlak15/bfl-go/mask"
+)
+
+// Sides of an image, combined to choose where it is extended.
+type Side int
+
+const (
+	SideTop Side = 1 << iota
+	SideRight
+	SideBottom
+	SideLeft
+
+	SideAll = SideTop | SideRight | SideBottom | SideLeft
+)
+
+// Parameters for extending an image beyond its borders.
+//
+// The image is extended either by explicit margins, or to a target size or aspect ratio, in
+// which case the added space is split evenly between the chosen sides of each axis.
+type OutpaintOptions struct {
+	// Pixels added on each side.
+	Top, Right, Bottom, Left int
+	// Target size of the extended image, at least as large as the original.
+	Width, Height int
+	// Target aspect ratio of the extended image, such as "16:9". The image is only extended,
+	// never cropped, to reach it.
+	AspectRatio AspectRatio
+	// Sides that may be extended to reach the target size or aspect ratio.
+	// Default: SideAll.
+	Sides Side
+	// Pixels of the original image along the new borders that are also repainted, to hide seams.
+	// Must leave part of the original image untouched.
+	Overlap int
+	// Radius in pixels over which the edges of the mask are softened.
+	Feather int
+	// Task used for generation, to set the prompt and other parameters. Its Image and Mask are
+	// replaced. Default: NewFluxProFillGenerate with the documented defaults.
+	Task *FluxProFillGenerate
+}
+
+// The margins to add on each side of an image of the given size.
+func (o *OutpaintOptions) margins(width int, height int) (top, right, bottom, left int, err error) {
+	targetW, targetH := o.Width, o.Height
+	if o.AspectRatio != "" {
+		if targetW != 0 || targetH != 0 {
+			return 0, 0, 0, 0, fmt.Errorf("both a size and an aspect ratio are set")
+		}
+		w, h, err := o.AspectRatio.Ratio()
+		if err != nil {
+			return 0, 0, 0, 0, err
+		}
+		targetW, targetH = width, height
+		if width*h < height*w {
+			targetW = (height*w + h - 1) / h
+		} else {
+			targetH = (width*h + w - 1) / w
+		}
+	}
+	if targetW == 0 && targetH == 0 {
+		return o.Top, o.Right, o.Bottom, o.Left, nil
+	}
+	if targetW == 0 {
+		targetW = width
+	}
+	if targetH == 0 {
+		targetH = height
+	}
+	if targetW < width || targetH < height {
+		return 0, 0, 0, 0, fmt.Errorf("target size %dx%d is smaller than the image size %dx%d", targetW, targetH, width, height)
+	}
+	sides := o.Sides
+	if sides == 0 {
+		sides = SideAll
+	}
+	split := func(extra int, first, second Side) (int, int, error) {
+		switch {
+		case extra == 0:
+			return 0, 0, nil
+		case sides&first != 0 && sides&second != 0:
+			return extra / 2, extra - extra/2, nil
+		case sides&first != 0:
+			return extra, 0, nil
+		case sides&second != 0:
+			return 0, extra, nil
+		default:
+			return 0, 0, fmt.Errorf("no side is allowed to be extended along this axis")
+		}
+	}
+	if left, right, err = split(targetW-width, SideLeft, SideRight); err != nil {
+		return 0, 0, 0, 0, err
+	}
+	if top, bottom, err = split(targetH-height, SideTop, SideBottom); err != nil {
+		return 0, 0, 0, 0, err
+	}
+	return top, right, bottom, left, nil
+}
+
+// Pad an image according to the options and create the mask selecting the added areas.
+// The added areas are filled by stretching the borders of the image.
+func PrepareOutpaint(img image.Image, opts OutpaintOptions) (image.Image, *mask.Mask, error) {
+	b := img.Bounds()
+	top, right, bottom, left, err := opts.margins(b.Dx(), b.Dy())
+	if err != nil {
+		return nil, nil, err
+	}
+	if top < 0 || right < 0 || bottom < 0 || left < 0 {
+		return nil, nil, fmt.Errorf("margins must not be negative")
+	}
+	if top+right+bottom+left == 0 {
+		return nil, nil, fmt.Errorf("nothing to outpaint")
+	}
+	src := imageutil.ToRGBA(img)
+	width, height := b.Dx()+left+right, b.Dy()+top+bottom
+	canvas := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		sy := min(max(y-top, 0), b.Dy()-1)
+		for x := 0; x < width; x++ {
+			sx := min(max(x-left, 0), b.Dx()-1)
+			canvas.SetRGBA(x, y, src.RGBAAt(sx, sy))
+		}
+	}
+	draw.Draw(canvas, image.Rect(left, top, left+b.Dx(), top+b.Dy()), src, image.Point{}, draw.Src)
+
+	m := mask.New(width, height)
+	keep := image.Rect(left, top, left+b.Dx(), top+b.Dy())
+	overlap := max(opts.Overlap, 0)
+	if top > 0 {
+		keep.Min.Y += overlap
+	}
+	if left > 0 {
+		keep.Min.X += overlap
+	}
+	if bottom > 0 {
+		keep.Max.Y -= overlap
+	}
+	if right > 0 {
+		keep.Max.X -= overlap
+	}
+	if keep.Dx() <= 0 || keep.Dy() <= 0 {
+		return nil, nil, fmt.Errorf("overlap of %d pixels leaves nothing of the %dx%d image", overlap, b.Dx(), b.Dy())
+	}
+	m.Rect(keep).Invert().Feather(opts.Feather)
+	return canvas, m, nil
+}
+
+// Extend an image beyond its borders with Flux Pro 1.0 Fill and return the extended image
+// along with the generation result. The extended image must not exceed 1440 pixels per side,
+// the largest size generated by the API; downsize larger images before extending them.
+func Outpaint(ctx context.Context, c *Client, img image.Image, opts OutpaintOptions, pollOpts ...PollOption) (image.Image, *GenerateResult, error) {
+	canvas, m, err := PrepareOutpaint(img, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if b := canvas.Bounds(); b.Dx() > MaxDimension || b.Dy() > MaxDimension {
+		return nil, nil, fmt.Errorf("extended image is %dx%d, at most %dx%d is supported", b.Dx(), b.Dy(), MaxDimension, MaxDimension)
+	}
+	task := NewFluxProFillGenerate("")
+	if opts.Task != nil {
+		copied := *opts.Task
+		task = &copied
+	}
+	if task.Image, err = ImageFromImage(canvas).Base64(ctx, c, ImageFormat("png")); err != nil {
+		return nil, nil, err
+	}
+	if task.Mask, err = m.Base64(); err != nil {
+		return nil, nil, err
+	}
+	result, err := Generate(ctx, c, task, pollOpts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	extended, err := result.DownloadImage(ctx, c)
+	if err != nil {
+		return nil, result, err
+	}
+	return extended, result, nil
+}
@@ -0,0 +1,178 @@
+package bfl
+
+import (
+	"context"
+	"time"
+)
+
+// A snapshot of an async task, emitted every time it is polled.
+type PollEvent struct {
+	TaskID   string
+	Status   StatusResponse
+	Progress float64
+	// Number of the poll request, starting at 1.
+	Attempt int
+	// Time elapsed since polling started.
+	Elapsed time.Duration
+	// The polled *ResultResponse[T, D]. See PollWithResponses for receiving it with its type.
+	Response any
+}
+
+// Receives an event for every response observed while polling.
+type ProgressObserver interface {
+	OnPoll(event PollEvent)
+}
+
+// A function implementing ProgressObserver.
+type ProgressFunc func(event PollEvent)
+
+func (f ProgressFunc) OnPoll(event PollEvent) {
+	f(event)
+}
+
+type pollOptions struct {
+	observers []ProgressObserver
+	events    []chan<- PollEvent
+	strategy  PollStrategy
+	maxWait   time.Duration
+	keepSlot  bool
+}
+
+// An option for configuring a single call to Poll.
+type PollOption func(o *pollOptions)
+
+// Notify the given observer about every polled response.
+func PollWithObserver(observer ProgressObserver) PollOption {
+	return func(o *pollOptions) {
+		o.observers = append(o.observers, observer)
+	}
+}
+
+// Call the given function for every polled response.
+func PollWithProgress(fn func(event PollEvent)) PollOption {
+	return PollWithObserver(ProgressFunc(fn))
+}
+
+// Call the given function with every polled response, typed after the task being polled.
+// Responses of other types, i.e. when polling a different kind of task, are ignored.
+func PollWithResponses[T Result, D Details](fn func(event PollEvent, res *ResultResponse[T, D])) PollOption {
+	return PollWithProgress(func(event PollEvent) {
+		if res, ok := event.Response.(*ResultResponse[T, D]); ok {
+			fn(event, res)
+		}
+	})
+}
+
+// Send an event to the given channel for every polled response.
+// Intermediate events are dropped rather than delaying polling when the channel is not ready to
+// receive, but the event of the terminal status is always sent unless the context is done.
+func PollWithEvents(events chan<- PollEvent) PollOption {
+	return func(o *pollOptions) {
+		o.events = append(o.events, events)
+	}
+}
+
+// Poll according to the given strategy instead of the client's.
+func PollWithStrategy(strategy PollStrategy) PollOption {
+	return func(o *pollOptions) {
+		o.strategy = strategy
+	}
+}
+
+// Keep the concurrency slot of the task when polling stops before the task reaches a terminal
+// status, for callers that keep waiting on the task by other means and call ReleaseTask themselves.
+func PollKeepingSlot() PollOption {
+	return func(o *pollOptions) {
+		o.keepSlot = true
+	}
+}
+
+// Give up polling with ErrMaxWaitExceeded after the given duration instead of the client's limit.
+func PollWithMaxWait(maxWait time.Duration) PollOption {
+	return func(o *pollOptions) {
+		o.maxWait = maxWait
+	}
+}
+
+func (o *pollOptions) notify(ctx context.Context, event PollEvent) {
+	for _, observer := range o.observers {
+		observer.OnPoll(event)
+	}
+	for _, events := range o.events {
+		if event.Status.IsTerminal() {
+			select {
+			case events <- event:
+			case <-ctx.Done():
+			}
+			continue
+		}
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
+
+// Poll the BFL API for the result of an async task.
+// The interval between polls is decided by the poll strategy, polling every second by default.
+// Polling stops once the task reaches a terminal status. Tasks that were moderated, failed
+// or could not be found are reported as a *ModerationError, *TaskFailedError or *TaskNotFoundError.
+// The concurrency slot of the task is released when Poll returns, even if it gives up early,
+// unless PollKeepingSlot is used.
+func Poll[T Result, D Details](ctx context.Context, c *Client, ar *AsyncResponse, opts ...PollOption) (*ResultResponse[T, D], error) {
+	o := pollOptions{
+		strategy: c.PollStrategy,
+		maxWait:  c.MaxPollWait,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if !o.keepSlot {
+		defer c.ReleaseTask(ar.ID)
+	}
+	if o.strategy == nil {
+		o.strategy = defaultPollStrategy
+	}
+	if o.maxWait > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, o.maxWait, ErrMaxWaitExceeded)
+		defer cancel()
+	}
+	start := time.Now()
+	attempts := 0
+	for {
+		resultResponse, err := fetchResult[T, D](ctx, c, ar.PollingURL, ar.ID)
+		if err != nil {
+			if cause := context.Cause(ctx); cause == ErrMaxWaitExceeded {
+				return nil, cause
+			}
+			return nil, err
+		}
+		attempts++
+		o.notify(ctx, PollEvent{
+			TaskID:   resultResponse.ID,
+			Status:   resultResponse.Status,
+			Progress: resultResponse.Progress,
+			Attempt:  attempts,
+			Elapsed:  time.Since(start),
+			Response: resultResponse,
+		})
+		if resultResponse.Status.IsTerminal() {
+			if err := resultResponse.Err(); err != nil {
+				return nil, err
+			}
+			return resultResponse, nil
+		}
+		state := PollState{
+			Attempt:  attempts,
+			Elapsed:  time.Since(start),
+			Status:   resultResponse.Status,
+			Progress: resultResponse.Progress,
+		}
+		select {
+		case <-time.After(nextPoll(o.strategy, state)):
+		case <-ctx.Done():
+			return nil, context.Cause(ctx)
+		}
+	}
+}
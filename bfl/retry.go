@@ -0,0 +1,150 @@
+package bfl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Policy for retrying requests that failed because of transient errors.
+//
+// Polls are retried on rate limits, server errors and connection failures. Submissions are only
+// retried when the API is known not to have accepted the task, i.e. on HTTP 429, HTTP 503 and
+// failures to establish a connection, so that a retry never creates a duplicate task.
+type RetryPolicy struct {
+	// Total number of attempts, including the first one. Values below 2 disable retries.
+	MaxAttempts int
+	// Delay before the first retry.
+	InitialBackoff time.Duration
+	// Upper bound for the delay between two attempts, not applied to Retry-After.
+	MaxBackoff time.Duration
+	// Factor by which the delay grows after every attempt.
+	Multiplier float64
+	// Fraction of the delay that is randomized, between 0 and 1.
+	Jitter float64
+}
+
+// A reasonable retry policy for most uses of the API.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     30 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+// Returned when a request failed after being retried, whatever the failure of its last attempt.
+type RetryError struct {
+	// Number of attempts that were made.
+	Attempts int
+	// The error of the last attempt.
+	Err error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("giving up after %d attempts: %v", e.Attempts, e.Err)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// The delay before the given retry, starting at 1.
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	d := float64(p.InitialBackoff)
+	if p.Multiplier > 0 {
+		d *= math.Pow(p.Multiplier, float64(retry-1))
+	}
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		d -= d * jitter * rand.Float64()
+	}
+	return time.Duration(d)
+}
+
+// Perform an HTTP call, retrying it according to the client's retry policy.
+// Idempotent requests are retried on any transient failure, others only when the request
+// is known to have been rejected before being processed.
+func (c *Client) do(ctx context.Context, method string, url string, payload []byte, idempotent bool) (*http.Response, []byte, error) {
+	maxAttempts := 1
+	if c.RetryPolicy != nil && c.RetryPolicy.MaxAttempts > 1 {
+		maxAttempts = c.RetryPolicy.MaxAttempts
+	}
+	for attempt := 1; ; attempt++ {
+		res, body, err := c.send(ctx, method, url, payload)
+		var retry bool
+		var wait time.Duration
+		if err != nil {
+			retry = ctx.Err() == nil && retryableTransportError(err, idempotent)
+		} else {
+			retry = retryableStatus(res.StatusCode, idempotent)
+			wait = retryAfter(res.Header)
+		}
+		if !retry || attempt >= maxAttempts {
+			if attempt == 1 || (err == nil && res.StatusCode < 400) {
+				return res, body, err
+			}
+			if err == nil {
+				err = responseError(res, body)
+			}
+			return nil, nil, &RetryError{Attempts: attempt, Err: err}
+		}
+		if backoff := c.RetryPolicy.backoff(attempt); wait < backoff {
+			wait = backoff
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+}
+
+func retryableStatus(code int, idempotent bool) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent
+	default:
+		return false
+	}
+}
+
+func retryableTransportError(err error, idempotent bool) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if idempotent {
+		return true
+	}
+	// Only retry submissions that never reached the server.
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// Parse the Retry-After header, which holds either a number of seconds or an HTTP date.
+func retryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if d := time.Until(date); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
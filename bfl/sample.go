@@ -0,0 +1,172 @@
+package bfl
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Default limit on the size of a downloaded sample.
+const defaultMaxSampleBytes = 50 << 20
+
+// A generated image downloaded from its sample URL.
+type Sample struct {
+	Data []byte
+	// The MIME type of the image, detected from its content.
+	ContentType string
+	// The format of the image, "jpeg" or "png" as requested with OutputFormat.
+	Format string
+	// Hex-encoded SHA-256 checksum of Data.
+	SHA256 string
+}
+
+// Decode the sample into an image.
+func (s *Sample) Image() (image.Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(s.Data))
+	return img, err
+}
+
+// The file extension matching the format of the sample, including the leading dot.
+func (s *Sample) Extension() string {
+	switch s.Format {
+	case "png":
+		return ".png"
+	case "jpeg":
+		return ".jpg"
+	default:
+		return ""
+	}
+}
+
+// Write the sample to a file and return its path. The extension matching the format of the
+// sample is appended when the path has none, and replaces any other extension, so that e.g.
+// a PNG sample saved as "render.jpg" is written to "render.png".
+func (s *Sample) Save(path string) (string, error) {
+	if want := s.Extension(); want != "" {
+		ext := filepath.Ext(path)
+		if lower := strings.ToLower(ext); lower != want && !(want == ".jpg" && lower == ".jpeg") {
+			path = strings.TrimSuffix(path, ext) + want
+		}
+	}
+	if err := os.WriteFile(path, s.Data, 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+type downloadOptions struct {
+	maxBytes int64
+	checksum string
+}
+
+// An option for configuring the download of a sample.
+type DownloadOption func(o *downloadOptions)
+
+// Fail downloads larger than the given number of bytes.
+// Default: 50 MiB.
+func DownloadMaxBytes(n int64) DownloadOption {
+	return func(o *downloadOptions) {
+		o.maxBytes = n
+	}
+}
+
+// Fail downloads whose SHA-256 checksum does not match the given hex-encoded checksum.
+func DownloadChecksum(sha256Hex string) DownloadOption {
+	return func(o *downloadOptions) {
+		o.checksum = strings.ToLower(sha256Hex)
+	}
+}
+
+// Download the generated image through the client's HTTP client.
+// Sample URLs are short-lived, so results should be downloaded soon after they are ready.
+func (r *GenerateResult) Download(ctx context.Context, c *Client, opts ...DownloadOption) (*Sample, error) {
+	o := downloadOptions{maxBytes: defaultMaxSampleBytes}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if r.SampleURL == "" {
+		return nil, fmt.Errorf("result has no sample URL")
+	}
+	data, err := c.download(ctx, r.SampleURL, o.maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	s := &Sample{
+		Data:        data,
+		ContentType: http.DetectContentType(data),
+		SHA256:      hex.EncodeToString(sum[:]),
+	}
+	if o.checksum != "" && o.checksum != s.SHA256 {
+		return nil, fmt.Errorf("checksum mismatch: expected %s, got %s", o.checksum, s.SHA256)
+	}
+	switch s.ContentType {
+	case "image/jpeg":
+		s.Format = "jpeg"
+	case "image/png":
+		s.Format = "png"
+	default:
+		return nil, fmt.Errorf("unexpected sample content type %s", s.ContentType)
+	}
+	return s, nil
+}
+
+// Download and decode the generated image.
+func (r *GenerateResult) DownloadImage(ctx context.Context, c *Client, opts ...DownloadOption) (image.Image, error) {
+	s, err := r.Download(ctx, c, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return s.Image()
+}
+
+// Download the generated image to a file and return its path. See Sample.Save.
+func (r *GenerateResult) Save(ctx context.Context, c *Client, path string, opts ...DownloadOption) (string, error) {
+	s, err := r.Download(ctx, c, opts...)
+	if err != nil {
+		return "", err
+	}
+	return s.Save(path)
+}
+
+// Fetch a URL outside of the API, such as a sample URL, without sending the API key.
+func (c *Client) download(ctx context.Context, url string, maxBytes int64) ([]byte, error) {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	res, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != 200 {
+		return nil, newAPIError(res, body)
+	}
+	if int64(len(body)) > maxBytes {
+		return nil, fmt.Errorf("download exceeds %d bytes", maxBytes)
+	}
+	return body, nil
+}
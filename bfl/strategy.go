@@ -0,0 +1,108 @@
+package bfl
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// Returned by Poll when a task did not reach a terminal status within the maximum wait time.
+var ErrMaxWaitExceeded = errors.New("maximum wait time exceeded")
+
+// The state of a task after being polled, used to decide when to poll it next.
+type PollState struct {
+	// Number of poll requests made so far, starting at 1.
+	Attempt int
+	// Time elapsed since polling started.
+	Elapsed time.Duration
+	// Status and progress reported by the last poll.
+	Status   StatusResponse
+	Progress float64
+}
+
+// Interval between polls of the default strategy, also used instead of intervals that are not positive.
+const defaultPollInterval = time.Second
+
+// Decides how long to wait between two polls of a task.
+// Poll waits for the default interval of one second when Next returns zero or a negative duration.
+type PollStrategy interface {
+	Next(state PollState) time.Duration
+}
+
+// Poll at a fixed interval. A zero interval polls every second.
+type FixedPollStrategy struct {
+	Interval time.Duration
+}
+
+func (s *FixedPollStrategy) Next(state PollState) time.Duration {
+	return s.Interval
+}
+
+// Poll with an interval that grows exponentially up to a maximum.
+type ExponentialPollStrategy struct {
+	// Interval after the first poll.
+	Initial time.Duration
+	// Upper bound for the interval.
+	Max time.Duration
+	// Factor by which the interval grows after every poll.
+	// Default: 2.
+	Multiplier float64
+}
+
+func (s *ExponentialPollStrategy) Next(state PollState) time.Duration {
+	multiplier := s.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+	d := float64(s.Initial) * math.Pow(multiplier, float64(state.Attempt-1))
+	if s.Max > 0 && d > float64(s.Max) {
+		return s.Max
+	}
+	return time.Duration(d)
+}
+
+// Poll slowly while a task has made little progress and quickly when it is close to completion.
+// The interval is interpolated linearly between Max at no progress and Min at full progress.
+// Pending tasks that do not report progress are polled at Max. Min and Max are swapped when
+// Min is the larger one.
+type AdaptivePollStrategy struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+func (s *AdaptivePollStrategy) Next(state PollState) time.Duration {
+	progress := state.Progress
+	// Progress may be reported either as a fraction or as a percentage.
+	if progress > 1 {
+		progress /= 100
+	}
+	progress = math.Max(0, math.Min(progress, 1))
+	lo, hi := min(s.Min, s.Max), max(s.Min, s.Max)
+	return hi - time.Duration(progress*float64(hi-lo))
+}
+
+// Poll at a fixed interval. The default strategy polls every second.
+func FixedPolling(interval time.Duration) PollStrategy {
+	return &FixedPollStrategy{Interval: interval}
+}
+
+// Poll with an exponentially growing interval, doubling from initial up to max.
+func ExponentialPolling(initial time.Duration, max time.Duration) PollStrategy {
+	return &ExponentialPollStrategy{Initial: initial, Max: max, Multiplier: 2}
+}
+
+// Poll with an interval between min and max depending on the progress of the task.
+func AdaptivePolling(min time.Duration, max time.Duration) PollStrategy {
+	return &AdaptivePollStrategy{Min: min, Max: max}
+}
+
+var defaultPollStrategy PollStrategy = FixedPolling(defaultPollInterval)
+
+// The interval before the next poll according to the strategy, falling back to the default
+// interval when the strategy returns a duration that is not positive.
+func nextPoll(strategy PollStrategy, state PollState) time.Duration {
+	if d := strategy.Next(state); d > 0 {
+		return d
+	}
+	return defaultPollInterval
+}
@@ -0,0 +1,111 @@
+package bfl
+
+import (
+	"context"
+)
+
+// A handle to a submitted async task.
+//
+// The task is polled in the background from the moment it is submitted until it reaches a
+// terminal status, the poll options give up, or Stop is called. See TrackWith for waiting on
+// the result by other means than polling.
+type Task[T Result, D Details] struct {
+	client *Client
+	ar     *AsyncResponse
+	cancel context.CancelFunc
+	done   chan struct{}
+	result *ResultResponse[T, D]
+	err    error
+}
+
+// A handle to an image generation task.
+type GenerationTask = Task[*GenerateResult, *GenerateDetails]
+
+// A handle to a finetuning task.
+type FinetuningTask = Task[*FinetuneResult, *FinetuneDetails]
+
+// Submit an async task and start polling for its result in the background.
+// Cancelling ctx only affects the submission; use Stop to stop polling.
+func Submit[T Result, D Details](ctx context.Context, c *Client, task AsyncTask, opts ...PollOption) (*Task[T, D], error) {
+	ar, err := c.AsyncRequest(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+	return Track[T, D](ctx, c, ar, opts...), nil
+}
+
+// Start polling in the background for the result of an already submitted task.
+func Track[T Result, D Details](ctx context.Context, c *Client, ar *AsyncResponse, opts ...PollOption) *Task[T, D] {
+	return TrackWith(ctx, c, ar, func(ctx context.Context) (*ResultResponse[T, D], error) {
+		return Poll[T, D](ctx, c, ar, opts...)
+	})
+}
+
+// Wait in the background for the result of an already submitted task with the given function
+// instead of polling, e.g. to receive it through a webhook. The function must return once its
+// context is done, which happens when Stop is called. The concurrency slot of the task is
+// released when it returns.
+func TrackWith[T Result, D Details](ctx context.Context, c *Client, ar *AsyncResponse, wait func(ctx context.Context) (*ResultResponse[T, D], error)) *Task[T, D] {
+	waitCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	t := &Task[T, D]{
+		client: c,
+		ar:     ar,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go func() {
+		defer close(t.done)
+		defer cancel()
+		defer c.ReleaseTask(ar.ID)
+		t.result, t.err = wait(waitCtx)
+	}()
+	return t
+}
+
+// Submit an image generation task and start polling for its result in the background.
+func (c *Client) SubmitGenerate(ctx context.Context, task GenerateTask, opts ...PollOption) (*GenerationTask, error) {
+	return Submit[*GenerateResult, *GenerateDetails](ctx, c, task, opts...)
+}
+
+// Submit a finetuning task and start polling for its result in the background.
+func (c *Client) SubmitFinetune(ctx context.Context, task FinetuneTask, opts ...PollOption) (*FinetuningTask, error) {
+	return Submit[*FinetuneResult, *FinetuneDetails](ctx, c, task, opts...)
+}
+
+// The ID of the task.
+func (t *Task[T, D]) ID() string {
+	return t.ar.ID
+}
+
+// The response returned when the task was submitted.
+func (t *Task[T, D]) AsyncResponse() *AsyncResponse {
+	return t.ar
+}
+
+// Request the current state of the task, independently of background polling.
+func (t *Task[T, D]) Status(ctx context.Context) (*ResultResponse[T, D], error) {
+	return fetchResult[T, D](ctx, t.client, t.ar.PollingURL, t.ar.ID)
+}
+
+// A channel that is closed once background polling is over.
+func (t *Task[T, D]) Done() <-chan struct{} {
+	return t.done
+}
+
+// Wait for background polling to be over and return its outcome.
+// Errors are the same as those returned by Poll.
+func (t *Task[T, D]) Wait(ctx context.Context) (*ResultResponse[T, D], error) {
+	select {
+	case <-t.done:
+		return t.result, t.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Stop background polling and wait for it to be over, releasing the concurrency slot of the task.
+// Pending and future calls to Wait return the cancellation error.
+func (t *Task[T, D]) Stop() {
+	t.cancel()
+	<-t.done
+}
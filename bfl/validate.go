@@ -0,0 +1,126 @@
+package bfl
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Kodlak15/bfl-go/mask"
+)
+
+// Collects field-level validation errors, shaped like those returned by the API.
+type validator struct {
+	errs []ValidationError
+}
+
+func (v *validator) add(field string, typ string, format string, args ...any) {
+	v.errs = append(v.errs, ValidationError{
+		Loc: []interface{}{"body", field},
+		Msg: fmt.Sprintf(format, args...),
+		Typ: typ,
+	})
+}
+
+func (v *validator) required(field string, value string) {
+	if value == "" {
+		v.add(field, "missing", "Field required")
+	}
+}
+
+func (v *validator) intRange(field string, value int, min int, max int) {
+	if value < min {
+		v.add(field, "greater_than_equal", "Input should be greater than or equal to %d", min)
+	} else if value > max {
+		v.add(field, "less_than_equal", "Input should be less than or equal to %d", max)
+	}
+}
+
+func (v *validator) floatRange(field string, value float64, min float64, max float64) {
+	if value < min {
+		v.add(field, "greater_than_equal", "Input should be greater than or equal to %g", min)
+	} else if value > max {
+		v.add(field, "less_than_equal", "Input should be less than or equal to %g", max)
+	}
+}
+
+// Check an optional integer, which is only constrained when set.
+func (v *validator) optIntRange(field string, value *int, min int, max int) {
+	if value != nil {
+		v.intRange(field, *value, min, max)
+	}
+}
+
+// Check an optional float, which is only constrained when set.
+func (v *validator) optFloatRange(field string, value *float64, min float64, max float64) {
+	if value != nil {
+		v.floatRange(field, *value, min, max)
+	}
+}
+
+// Check a dimension in pixels, which must be a multiple of 32 within the given range.
+func (v *validator) dimension(field string, value int, min int, max int) {
+	v.intRange(field, value, min, max)
+	if value%32 != 0 {
+		v.add(field, "multiple_of", "Input should be a multiple of 32")
+	}
+}
+
+func (v *validator) oneOf(field string, value string, allowed ...string) {
+	if !slices.Contains(allowed, value) {
+		v.add(field, "literal_error", "Input should be one of %q", allowed)
+	}
+}
+
+func (v *validator) outputFormat(value string) {
+	if value != "" {
+		v.oneOf("output_format", value, "jpeg", "png")
+	}
+}
+
+func (v *validator) webhook(url string) {
+	if len(url) > 2083 {
+		v.add("webhook_url", "string_too_long", "String should have at most 2083 characters")
+	}
+}
+
+// Check that a base64-encoded mask matches its image, when both are set.
+func (v *validator) mask(img string, m string) {
+	if img != "" && m != "" {
+		if err := mask.ValidateBase64(img, m); err != nil {
+			v.add("mask", "value_error", "%v", err)
+		}
+	}
+}
+
+// Returned by the Validate method of tasks, and by AsyncRequest before sending anything, when the
+// parameters of a task are invalid. Validation failures reported by the API are returned as
+// *HTTPValidationError instead, so this error means the task was not submitted.
+type TaskValidationError struct {
+	Detail []ValidationError
+}
+
+func (e *TaskValidationError) Error() string {
+	n := len(e.Detail)
+	if n == 0 {
+		return "Invalid task"
+	}
+	msg := ""
+	for i, detail := range e.Detail {
+		msg += fmt.Sprintf("Invalid task (%d/%d): ", i+1, n)
+		if len(detail.Loc) > 0 {
+			msg += fmt.Sprintf("%v: ", detail.Loc[len(detail.Loc)-1])
+		}
+		msg += detail.Msg
+		if i != n-1 {
+			msg += "\n"
+		}
+	}
+	return msg
+}
+
+// The collected errors as a *TaskValidationError, or nil if there are none.
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return &TaskValidationError{Detail: v.errs}
+}
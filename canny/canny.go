@@ -0,0 +1,223 @@
+// Package canny detects edges locally with the Canny algorithm, to preview and submit the
+// preprocessed images of the Canny models.
+//
+// Thresholds follow the semantics of CannyLowThreshold and CannyHighThreshold: they apply to
+// the L1 magnitude of the 3x3 Sobel gradient of the grayscale image, like OpenCV's Canny.
+// Pixels above the high threshold are edges, and pixels above the low threshold are edges when
+// connected to another edge.
+package canny
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+
+	"github.com/Kodlak15/bfl-go/bfl"
+	"github.com/Kodlak15/bfl-go/internal/imageutil"
+)
+
+// Defaults of the Canny models.
+const (
+	DefaultLowThreshold  = 50
+	DefaultHighThreshold = 200
+)
+
+type Options struct {
+	LowThreshold  int
+	HighThreshold int
+	// Standard deviation of a gaussian blur applied before detection to reduce noise.
+	// No blur is applied when zero.
+	Sigma float64
+}
+
+// Detect the edges of an image with the given thresholds. Edges are white on black.
+func Detect(img image.Image, low int, high int) *image.Gray {
+	return DetectWithOptions(img, Options{LowThreshold: low, HighThreshold: high})
+}
+
+// Detect the edges of an image. Edges are white on black.
+func DetectWithOptions(img image.Image, opts Options) *image.Gray {
+	low, high := opts.LowThreshold, opts.HighThreshold
+	if low > high {
+		low, high = high, low
+	}
+	gray := imageutil.ToGray(img)
+	if opts.Sigma > 0 {
+		gray = gaussianBlur(gray, opts.Sigma)
+	}
+	w, h := gray.Bounds().Dx(), gray.Bounds().Dy()
+	mag := make([]int, w*h)
+	dir := make([]uint8, w*h)
+	at := func(x, y int) int {
+		return int(gray.Pix[min(max(y, 0), h-1)*gray.Stride+min(max(x, 0), w-1)])
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			gx := at(x+1, y-1) + 2*at(x+1, y) + at(x+1, y+1) - at(x-1, y-1) - 2*at(x-1, y) - at(x-1, y+1)
+			gy := at(x-1, y+1) + 2*at(x, y+1) + at(x+1, y+1) - at(x-1, y-1) - 2*at(x, y-1) - at(x+1, y-1)
+			mag[y*w+x] = abs(gx) + abs(gy)
+			dir[y*w+x] = direction(gx, gy)
+		}
+	}
+
+	// Keep local maxima along the gradient direction, classified as strong or weak edges.
+	const (
+		none uint8 = iota
+		weak
+		strong
+	)
+	class := make([]uint8, w*h)
+	offsets := [4][2]int{{1, 0}, {1, 1}, {0, 1}, {-1, 1}}
+	var stack []int
+	for y := 1; y < h-1; y++ {
+		for x := 1; x < w-1; x++ {
+			i := y*w + x
+			m := mag[i]
+			if m <= low {
+				continue
+			}
+			o := offsets[dir[i]]
+			before, after := mag[(y-o[1])*w+x-o[0]], mag[(y+o[1])*w+x+o[0]]
+			if m <= before || m < after {
+				continue
+			}
+			if m > high {
+				class[i] = strong
+				stack = append(stack, i)
+			} else {
+				class[i] = weak
+			}
+		}
+	}
+
+	// Hysteresis: promote weak edges connected to strong edges.
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		x, y := i%w, i/w
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				nx, ny := x+dx, y+dy
+				if nx < 0 || ny < 0 || nx >= w || ny >= h {
+					continue
+				}
+				if j := ny*w + nx; class[j] == weak {
+					class[j] = strong
+					stack = append(stack, j)
+				}
+			}
+		}
+	}
+
+	edges := image.NewGray(image.Rect(0, 0, w, h))
+	for i, c := range class {
+		if c == strong {
+			edges.Pix[i/w*edges.Stride+i%w] = 255
+		}
+	}
+	return edges
+}
+
+// Quantize the gradient direction into horizontal, diagonal, vertical or anti-diagonal.
+func direction(gx int, gy int) uint8 {
+	angle := math.Atan2(float64(gy), float64(gx)) * 180 / math.Pi
+	if angle < 0 {
+		angle += 180
+	}
+	switch {
+	case angle < 22.5 || angle >= 157.5:
+		return 0
+	case angle < 67.5:
+		return 1
+	case angle < 112.5:
+		return 2
+	default:
+		return 3
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func gaussianBlur(src *image.Gray, sigma float64) *image.Gray {
+	radius := int(math.Ceil(3 * sigma))
+	kernel := make([]float64, 2*radius+1)
+	var sum float64
+	for i := range kernel {
+		d := float64(i - radius)
+		kernel[i] = math.Exp(-d * d / (2 * sigma * sigma))
+		sum += kernel[i]
+	}
+	for i := range kernel {
+		kernel[i] /= sum
+	}
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	tmp := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var v float64
+			for k, weight := range kernel {
+				sx := min(max(x+k-radius, 0), w-1)
+				v += weight * float64(src.Pix[y*src.Stride+sx])
+			}
+			tmp[y*w+x] = v
+		}
+	}
+	dst := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var v float64
+			for k, weight := range kernel {
+				sy := min(max(y+k-radius, 0), h-1)
+				v += weight * tmp[sy*w+x]
+			}
+			dst.Pix[y*dst.Stride+x] = uint8(math.Round(v))
+		}
+	}
+	return dst
+}
+
+// Encode an edge map as base64 PNG, the form expected by PreprocessedImage.
+func Base64(edges image.Image) (string, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, edges); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// Detect the edges of an image with the thresholds of a Canny task, or their defaults when
+// unset, and set the result as its PreprocessedImage. Returns the edge map that was set.
+func Preprocess(task bfl.GenerateTask, img image.Image) (*image.Gray, error) {
+	var low, high *int
+	var preprocessed *string
+	switch t := task.(type) {
+	case *bfl.FluxProCannyGenerate:
+		low, high, preprocessed = t.CannyLowThreshold, t.CannyHighThreshold, &t.PreprocessedImage
+	case *bfl.FluxProCannyFinetunedGenerate:
+		low, high, preprocessed = t.CannyLowThreshold, t.CannyHighThreshold, &t.PreprocessedImage
+	default:
+		return nil, fmt.Errorf("%T is not a Canny task", task)
+	}
+	opts := Options{LowThreshold: DefaultLowThreshold, HighThreshold: DefaultHighThreshold}
+	if low != nil {
+		opts.LowThreshold = *low
+	}
+	if high != nil {
+		opts.HighThreshold = *high
+	}
+	edges := DetectWithOptions(img, opts)
+	encoded, err := Base64(edges)
+	if err != nil {
+		return nil, err
+	}
+	*preprocessed = encoded
+	return edges, nil
+}
@@ -1,3 +1,5 @@
 module github.com/Kodlak15/bfl-go
 
 go 1.23.6
+
+require golang.org/x/image v0.30.0
@@ -0,0 +1,102 @@
+// Package imageutil holds image processing helpers shared by the packages of this module.
+package imageutil
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// Convert an image to RGBA with its bounds starting at the origin.
+func ToRGBA(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+	return dst
+}
+
+// Convert an image to grayscale with its bounds starting at the origin.
+func ToGray(img image.Image) *image.Gray {
+	b := img.Bounds()
+	dst := image.NewGray(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+	return dst
+}
+
+// Scale an image to the given size, averaging the source pixels covered by each destination pixel
+// when shrinking and interpolating bilinearly when enlarging.
+func Resize(img image.Image, width int, height int) *image.RGBA {
+	src := ToRGBA(img)
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	if sw == 0 || sh == 0 || width == 0 || height == 0 {
+		return dst
+	}
+	if width <= sw && height <= sh {
+		for y := 0; y < height; y++ {
+			y0, y1 := y*sh/height, max((y+1)*sh/height, y*sh/height+1)
+			for x := 0; x < width; x++ {
+				x0, x1 := x*sw/width, max((x+1)*sw/width, x*sw/width+1)
+				var r, g, b, a, n uint32
+				for sy := y0; sy < y1; sy++ {
+					for sx := x0; sx < x1; sx++ {
+						c := src.RGBAAt(sx, sy)
+						r += uint32(c.R)
+						g += uint32(c.G)
+						b += uint32(c.B)
+						a += uint32(c.A)
+						n++
+					}
+				}
+				dst.SetRGBA(x, y, color.RGBA{uint8(r / n), uint8(g / n), uint8(b / n), uint8(a / n)})
+			}
+		}
+		return dst
+	}
+	for y := 0; y < height; y++ {
+		fy := (float64(y)+0.5)*float64(sh)/float64(height) - 0.5
+		for x := 0; x < width; x++ {
+			fx := (float64(x)+0.5)*float64(sw)/float64(width) - 0.5
+			dst.SetRGBA(x, y, bilinear(src, fx, fy))
+		}
+	}
+	return dst
+}
+
+func bilinear(src *image.RGBA, fx float64, fy float64) color.RGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	x0, y0 := int(math.Floor(fx)), int(math.Floor(fy))
+	dx, dy := fx-float64(x0), fy-float64(y0)
+	at := func(x, y int) color.RGBA {
+		return src.RGBAAt(min(max(x, 0), w-1), min(max(y, 0), h-1))
+	}
+	c00, c10, c01, c11 := at(x0, y0), at(x0+1, y0), at(x0, y0+1), at(x0+1, y0+1)
+	mix := func(a, b, c, d uint8) uint8 {
+		top := float64(a)*(1-dx) + float64(b)*dx
+		bottom := float64(c)*(1-dx) + float64(d)*dx
+		return uint8(top*(1-dy) + bottom*dy + 0.5)
+	}
+	return color.RGBA{
+		R: mix(c00.R, c10.R, c01.R, c11.R),
+		G: mix(c00.G, c10.G, c01.G, c11.G),
+		B: mix(c00.B, c10.B, c01.B, c11.B),
+		A: mix(c00.A, c10.A, c01.A, c11.A),
+	}
+}
+
+// The largest size with the same aspect ratio as width x height that fits within maxSide on
+// both sides and maxPixels in area. Limits of zero are ignored.
+func FitWithin(width int, height int, maxSide int, maxPixels int) (int, int) {
+	scale := 1.0
+	if maxSide > 0 && max(width, height) > maxSide {
+		scale = float64(maxSide) / float64(max(width, height))
+	}
+	if maxPixels > 0 && float64(width*height)*scale*scale > float64(maxPixels) {
+		scale = math.Sqrt(float64(maxPixels) / float64(width*height))
+	}
+	if scale >= 1 {
+		return width, height
+	}
+	return max(int(float64(width)*scale), 1), max(int(float64(height)*scale), 1)
+}
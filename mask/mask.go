@@ -0,0 +1,245 @@
+// Package mask builds black and white masks for inpainting with the Fill models.
+//
+// White areas of a mask are repainted by the model, black areas are kept as they are.
+package mask
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"image/png"
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/Kodlak15/bfl-go/internal/imageutil"
+)
+
+// A mask being built. Every drawing method modifies the mask and returns it for chaining.
+type Mask struct {
+	img *image.Gray
+}
+
+// An empty mask of the given size, keeping the whole image.
+func New(width int, height int) *Mask {
+	return &Mask{img: image.NewGray(image.Rect(0, 0, width, height))}
+}
+
+// An empty mask with the same size as the given image.
+func ForImage(img image.Image) *Mask {
+	return New(img.Bounds().Dx(), img.Bounds().Dy())
+}
+
+// A mask selecting the transparent areas of an image, proportionally to their transparency.
+func FromAlpha(img image.Image) *Mask {
+	b := img.Bounds()
+	m := ForImage(img)
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			_, _, _, a := img.At(b.Min.X+x, b.Min.Y+y).RGBA()
+			m.img.SetGray(x, y, color.Gray{Y: 255 - uint8(a>>8)})
+		}
+	}
+	return m
+}
+
+// Use an existing image as a mask, converting it to grayscale.
+func FromImage(img image.Image) *Mask {
+	return &Mask{img: imageutil.ToGray(img)}
+}
+
+func (m *Mask) Bounds() image.Rectangle {
+	return m.img.Bounds()
+}
+
+// The mask as a grayscale image.
+func (m *Mask) Image() *image.Gray {
+	return m.img
+}
+
+// Select a rectangle.
+func (m *Mask) Rect(r image.Rectangle) *Mask {
+	r = r.Intersect(m.img.Bounds())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			m.img.SetGray(x, y, color.Gray{Y: 255})
+		}
+	}
+	return m
+}
+
+// Select an ellipse with the given center and radii.
+func (m *Mask) Ellipse(center image.Point, rx int, ry int) *Mask {
+	if rx <= 0 || ry <= 0 {
+		return m
+	}
+	r := image.Rect(center.X-rx, center.Y-ry, center.X+rx+1, center.Y+ry+1).Intersect(m.img.Bounds())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			dx := float64(x-center.X) / float64(rx)
+			dy := float64(y-center.Y) / float64(ry)
+			if dx*dx+dy*dy <= 1 {
+				m.img.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+	return m
+}
+
+// Select the inside of a polygon, using the even-odd rule for self-intersecting polygons.
+func (m *Mask) Polygon(points ...image.Point) *Mask {
+	if len(points) < 3 {
+		return m
+	}
+	b := m.img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		cy := float64(y) + 0.5
+		var xs []float64
+		for i := range points {
+			p, q := points[i], points[(i+1)%len(points)]
+			py, qy := float64(p.Y), float64(q.Y)
+			if (py <= cy) == (qy <= cy) {
+				continue
+			}
+			xs = append(xs, float64(p.X)+(cy-py)/(qy-py)*float64(q.X-p.X))
+		}
+		sort.Float64s(xs)
+		for i := 0; i+1 < len(xs); i += 2 {
+			from := max(int(math.Ceil(xs[i]-0.5)), b.Min.X)
+			to := min(int(math.Ceil(xs[i+1]-0.5)), b.Max.X)
+			for x := from; x < to; x++ {
+				m.img.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+	return m
+}
+
+// Swap the selected and unselected areas.
+func (m *Mask) Invert() *Mask {
+	for i, v := range m.img.Pix {
+		m.img.Pix[i] = 255 - v
+	}
+	return m
+}
+
+// Soften the edges of the selection over roughly the given radius in pixels,
+// so that the repainted areas blend into the rest of the image.
+func (m *Mask) Feather(radius int) *Mask {
+	if radius <= 0 {
+		return m
+	}
+	// Three box blurs approximate a gaussian blur.
+	box := max(radius/2, 1)
+	for i := 0; i < 3; i++ {
+		m.img = boxBlur(m.img, box)
+	}
+	return m
+}
+
+// Blur horizontally then vertically with a box of the given radius.
+func boxBlur(src *image.Gray, radius int) *image.Gray {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	tmp := image.NewGray(src.Bounds())
+	dst := image.NewGray(src.Bounds())
+	blur := func(get func(int) uint8, set func(int, uint8), n int) {
+		sum := 0
+		for i := -radius; i <= radius; i++ {
+			sum += int(get(min(max(i, 0), n-1)))
+		}
+		for i := 0; i < n; i++ {
+			set(i, uint8(sum/(2*radius+1)))
+			sum += int(get(min(i+radius+1, n-1))) - int(get(max(i-radius, 0)))
+		}
+	}
+	for y := 0; y < h; y++ {
+		blur(func(x int) uint8 { return src.Pix[y*src.Stride+x] },
+			func(x int, v uint8) { tmp.Pix[y*tmp.Stride+x] = v }, w)
+	}
+	for x := 0; x < w; x++ {
+		blur(func(y int) uint8 { return tmp.Pix[y*tmp.Stride+x] },
+			func(y int, v uint8) { dst.Pix[y*dst.Stride+x] = v }, h)
+	}
+	return dst
+}
+
+// Encode the mask as PNG.
+func (m *Mask) PNG() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, m.img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Encode the mask as base64 PNG, the form expected by the Mask fields of the Fill tasks.
+func (m *Mask) Base64() (string, error) {
+	data, err := m.PNG()
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// Check that a mask can be used with an image: both must have the same size, and the mask
+// must be grayscale with some area to repaint.
+func Validate(img image.Image, mask image.Image) error {
+	ib, mb := img.Bounds(), mask.Bounds()
+	if ib.Dx() != mb.Dx() || ib.Dy() != mb.Dy() {
+		return fmt.Errorf("mask is %dx%d but image is %dx%d", mb.Dx(), mb.Dy(), ib.Dx(), ib.Dy())
+	}
+	painted := false
+	if gray, ok := mask.(*image.Gray); ok {
+		for y := mb.Min.Y; y < mb.Max.Y && !painted; y++ {
+			row := gray.Pix[gray.PixOffset(mb.Min.X, y):gray.PixOffset(mb.Max.X, y)]
+			painted = slices.ContainsFunc(row, func(v uint8) bool { return v > 0x10 })
+		}
+	} else {
+		for y := mb.Min.Y; y < mb.Max.Y; y++ {
+			for x := mb.Min.X; x < mb.Max.X; x++ {
+				r, g, b, _ := mask.At(x, y).RGBA()
+				// Allow small differences introduced by lossy encoding.
+				if diff(r, g) > 0x1000 || diff(g, b) > 0x1000 {
+					return fmt.Errorf("mask is not black and white at (%d, %d)", x, y)
+				}
+				painted = painted || r > 0x1000
+			}
+		}
+	}
+	if !painted {
+		return fmt.Errorf("mask is entirely black, nothing would be repainted")
+	}
+	return nil
+}
+
+func diff(a uint32, b uint32) uint32 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// Check that base64-encoded image and mask can be used together. See Validate.
+func ValidateBase64(img string, mask string) error {
+	decodedImg, err := decodeBase64(img)
+	if err != nil {
+		return fmt.Errorf("invalid image: %w", err)
+	}
+	decodedMask, err := decodeBase64(mask)
+	if err != nil {
+		return fmt.Errorf("invalid mask: %w", err)
+	}
+	return Validate(decodedImg, decodedMask)
+}
+
+func decodeBase64(s string) (image.Image, error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
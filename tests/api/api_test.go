@@ -1 +1,578 @@
 package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Kodlak15/bfl-go/bfl"
+)
+
+func testTask() *bfl.FluxDevGenerate {
+	return &bfl.FluxDevGenerate{Prompt: "test", Width: 1024, Height: 768}
+}
+
+func TestPollTerminalStatus(t *testing.T) {
+	cases := []struct {
+		status bfl.StatusResponse
+		check  func(err error) bool
+	}{
+		{bfl.StatusRequestModerated, func(err error) bool { var e *bfl.ModerationError; return errors.As(err, &e) }},
+		{bfl.StatusContentModerated, func(err error) bool { var e *bfl.ModerationError; return errors.As(err, &e) }},
+		{bfl.StatusError, func(err error) bool { var e *bfl.TaskFailedError; return errors.As(err, &e) }},
+		{bfl.StatusTaskNotFound, func(err error) bool { var e *bfl.TaskNotFoundError; return errors.As(err, &e) }},
+	}
+	for _, tc := range cases {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"id":"abc","status":%q}`, tc.status)
+		}))
+		client := bfl.NewClient("key", srv.URL)
+		ar := &bfl.AsyncResponse{ID: "abc", PollingURL: srv.URL + "/v1/get_result?id=abc"}
+		_, err := bfl.Poll[*bfl.GenerateResult, *bfl.GenerateDetails](context.Background(), client, ar)
+		srv.Close()
+		if !tc.check(err) {
+			t.Errorf("status %q: unexpected error %v", tc.status, err)
+		}
+	}
+}
+
+func TestPollReady(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"abc","status":"Ready","result":{"sample":"https://example.com/a.jpg"}}`)
+	}))
+	defer srv.Close()
+	client := bfl.NewClient("key", srv.URL)
+	ar := &bfl.AsyncResponse{ID: "abc", PollingURL: srv.URL + "/v1/get_result?id=abc"}
+	res, err := bfl.Poll[*bfl.GenerateResult, *bfl.GenerateDetails](context.Background(), client, ar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Result.SampleURL != "https://example.com/a.jpg" {
+		t.Errorf("unexpected sample url %q", res.Result.SampleURL)
+	}
+}
+
+type recordingTransport struct {
+	requests []*http.Request
+}
+
+func (rt *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.requests = append(rt.requests, req)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestClientOptions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Key") != "key" || r.Header.Get("X-Team") != "imaging" || r.UserAgent() != "bfl-test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"id":"abc","polling_url":"`+"http://"+r.Host+`/v1/get_result?id=abc"}`)
+	}))
+	defer srv.Close()
+	transport := &recordingTransport{}
+	client := bfl.NewClientWithOptions("key", srv.URL,
+		bfl.WithHTTPClient(&http.Client{Transport: transport}),
+		bfl.WithUserAgent("bfl-test"),
+		bfl.WithHeader("X-Team", "imaging"),
+	)
+	ar, err := client.AsyncRequest(context.Background(), testTask())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ar.ID != "abc" {
+		t.Errorf("unexpected task id %q", ar.ID)
+	}
+	if len(transport.requests) != 1 {
+		t.Errorf("expected 1 request through the custom transport, got %d", len(transport.requests))
+	}
+}
+
+func TestRetryAfterRateLimit(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, `{"id":"abc","polling_url":"unused"}`)
+	}))
+	defer srv.Close()
+	policy := bfl.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, Multiplier: 2}
+	client := bfl.NewClientWithOptions("key", srv.URL, bfl.WithRetryPolicy(policy))
+	if _, err := client.AsyncRequest(context.Background(), testTask()); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	policy := bfl.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+	client := bfl.NewClientWithOptions("key", srv.URL, bfl.WithRetryPolicy(policy))
+
+	// Submissions are not retried on errors that may have created a task.
+	if _, err := client.AsyncRequest(context.Background(), testTask()); err == nil {
+		t.Fatal("expected an error")
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 submission, got %d", calls)
+	}
+
+	calls = 0
+	_, err := bfl.GetResult[*bfl.GenerateResult, *bfl.GenerateDetails](context.Background(), client, "abc")
+	var retryErr *bfl.RetryError
+	if !errors.As(err, &retryErr) || retryErr.Attempts != 3 {
+		t.Fatalf("expected a retry error after 3 attempts, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestActiveTaskReleasedWhenPollingStops(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			fmt.Fprint(w, `{"id":"abc","polling_url":"http://`+r.Host+`/v1/get_result?id=abc"}`)
+			return
+		}
+		fmt.Fprint(w, `{"id":"abc","status":"Pending"}`)
+	}))
+	defer srv.Close()
+	client := bfl.NewClientWithOptions("key", srv.URL, bfl.WithMaxActiveTasks(1), bfl.WithPollStrategy(bfl.FixedPolling(time.Millisecond)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := bfl.Generate(ctx, client, testTask()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to be exceeded, got %v", err)
+	}
+	if n := client.ActiveTasks(); n != 0 {
+		t.Fatalf("expected the slot of a cancelled Generate to be released, got %d active tasks", n)
+	}
+
+	ar, err := client.AsyncRequest(context.Background(), testTask())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = bfl.Poll[*bfl.GenerateResult, *bfl.GenerateDetails](context.Background(), client, ar, bfl.PollWithMaxWait(20*time.Millisecond))
+	if !errors.Is(err, bfl.ErrMaxWaitExceeded) {
+		t.Fatalf("expected the max wait to be exceeded, got %v", err)
+	}
+	if n := client.ActiveTasks(); n != 0 {
+		t.Fatalf("expected the slot of a timed out poll to be released, got %d active tasks", n)
+	}
+}
+
+func TestRetryErrorOnlyAfterRetries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	// Without a retry policy, failures are returned as is.
+	client := bfl.NewClient("key", srv.URL)
+	_, err := bfl.GetResult[*bfl.GenerateResult, *bfl.GenerateDetails](context.Background(), client, "abc")
+	var retryErr *bfl.RetryError
+	if errors.As(err, &retryErr) || !errors.Is(err, bfl.ErrServer) {
+		t.Fatalf("expected a bare server error, got %v", err)
+	}
+
+	// A retried request ending on a non-retryable status still reports its attempts.
+	calls.Store(0)
+	policy := bfl.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+	client = bfl.NewClientWithOptions("key", srv.URL, bfl.WithRetryPolicy(policy))
+	_, err = bfl.GetResult[*bfl.GenerateResult, *bfl.GenerateDetails](context.Background(), client, "abc")
+	var apiErr *bfl.APIError
+	if !errors.As(err, &retryErr) || retryErr.Attempts != 2 || !errors.As(err, &apiErr) || apiErr.StatusCode != 404 {
+		t.Fatalf("expected a retry error after 2 attempts ending on a 404, got %v", err)
+	}
+}
+
+func TestMaxActiveTasks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			fmt.Fprint(w, `{"id":"abc","polling_url":"http://`+r.Host+`/v1/get_result?id=abc"}`)
+			return
+		}
+		fmt.Fprint(w, `{"id":"abc","status":"Ready","result":{}}`)
+	}))
+	defer srv.Close()
+	client := bfl.NewClientWithOptions("key", srv.URL, bfl.WithMaxActiveTasks(1), bfl.WithRateLimit(1000, 10))
+	ar, err := client.AsyncRequest(context.Background(), testTask())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := client.ActiveTasks(); n != 1 {
+		t.Fatalf("expected 1 active task, got %d", n)
+	}
+
+	// A second submission must wait for the first task to finish.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.AsyncRequest(ctx, testTask()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the submission to block, got %v", err)
+	}
+
+	if _, err := bfl.Poll[*bfl.GenerateResult, *bfl.GenerateDetails](context.Background(), client, ar); err != nil {
+		t.Fatal(err)
+	}
+	if n := client.ActiveTasks(); n != 0 {
+		t.Fatalf("expected no active tasks, got %d", n)
+	}
+	if _, err := client.AsyncRequest(context.Background(), testTask()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	cases := []struct {
+		code int
+		kind error
+	}{
+		{http.StatusUnauthorized, bfl.ErrAuthentication},
+		{http.StatusForbidden, bfl.ErrAuthentication},
+		{http.StatusPaymentRequired, bfl.ErrInsufficientCredits},
+		{http.StatusTooManyRequests, bfl.ErrRateLimited},
+		{http.StatusBadGateway, bfl.ErrServer},
+	}
+	for _, tc := range cases {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Request-Id", "req-1")
+			w.WriteHeader(tc.code)
+			fmt.Fprint(w, `{"detail":"nope"}`)
+		}))
+		client := bfl.NewClient("key", srv.URL)
+		_, err := client.AsyncRequest(context.Background(), testTask())
+		srv.Close()
+		if !errors.Is(err, tc.kind) {
+			t.Errorf("status %d: expected %v, got %v", tc.code, tc.kind, err)
+		}
+		var apiErr *bfl.APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("status %d: expected an APIError, got %v", tc.code, err)
+		}
+		if apiErr.StatusCode != tc.code || apiErr.RequestID != "req-1" || apiErr.Detail != "nope" {
+			t.Errorf("status %d: unexpected error fields %+v", tc.code, apiErr)
+		}
+	}
+}
+
+func TestPollProgress(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			fmt.Fprint(w, `{"id":"abc","status":"Pending","progress":0.5}`)
+			return
+		}
+		fmt.Fprint(w, `{"id":"abc","status":"Ready","progress":1,"result":{}}`)
+	}))
+	defer srv.Close()
+	client := bfl.NewClient("key", srv.URL)
+	ar := &bfl.AsyncResponse{ID: "abc", PollingURL: srv.URL + "/v1/get_result?id=abc"}
+	var events []bfl.PollEvent
+	_, err := bfl.Poll[*bfl.GenerateResult, *bfl.GenerateDetails](context.Background(), client, ar,
+		bfl.PollWithProgress(func(event bfl.PollEvent) { events = append(events, event) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if events[0].Status != bfl.StatusPending || events[0].Progress != 0.5 || events[0].Attempt != 1 {
+		t.Errorf("unexpected first event %+v", events[0])
+	}
+	if events[1].Status != bfl.StatusReady || events[1].Attempt != 2 {
+		t.Errorf("unexpected last event %+v", events[1])
+	}
+}
+
+func TestPollTypedResponsesAndTerminalEvent(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			fmt.Fprint(w, `{"id":"abc","status":"Pending"}`)
+			return
+		}
+		fmt.Fprint(w, `{"id":"abc","status":"Ready","result":{"sample":"https://example.com/a.jpg"}}`)
+	}))
+	defer srv.Close()
+	client := bfl.NewClientWithOptions("key", srv.URL, bfl.WithPollStrategy(bfl.FixedPolling(time.Millisecond)))
+	ar := &bfl.AsyncResponse{ID: "abc", PollingURL: srv.URL + "/v1/get_result?id=abc"}
+
+	// Nobody receives from the channel while polling, so only the terminal event may be kept.
+	events := make(chan bfl.PollEvent)
+	received := make(chan bfl.PollEvent, 1)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		received <- <-events
+	}()
+	var last *bfl.ResultResponse[*bfl.GenerateResult, *bfl.GenerateDetails]
+	_, err := bfl.Poll[*bfl.GenerateResult, *bfl.GenerateDetails](context.Background(), client, ar,
+		bfl.PollWithEvents(events),
+		bfl.PollWithResponses(func(event bfl.PollEvent, res *bfl.ResultResponse[*bfl.GenerateResult, *bfl.GenerateDetails]) {
+			last = res
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event := <-received; event.Status != bfl.StatusReady {
+		t.Errorf("expected the terminal event, got %+v", event)
+	}
+	if last == nil || last.Result.SampleURL != "https://example.com/a.jpg" {
+		t.Errorf("unexpected typed response %+v", last)
+	}
+}
+
+func TestPollStrategyAndMaxWait(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		fmt.Fprint(w, `{"id":"abc","status":"Pending","progress":0.1}`)
+	}))
+	defer srv.Close()
+	client := bfl.NewClientWithOptions("key", srv.URL,
+		bfl.WithPollStrategy(bfl.FixedPolling(time.Millisecond)),
+		bfl.WithMaxPollWait(100*time.Millisecond),
+	)
+	ar := &bfl.AsyncResponse{ID: "abc", PollingURL: srv.URL + "/v1/get_result?id=abc"}
+	_, err := bfl.Poll[*bfl.GenerateResult, *bfl.GenerateDetails](context.Background(), client, ar)
+	if !errors.Is(err, bfl.ErrMaxWaitExceeded) {
+		t.Fatalf("expected ErrMaxWaitExceeded, got %v", err)
+	}
+	if n := calls.Load(); n < 5 {
+		t.Errorf("expected frequent polling, got %d calls", n)
+	}
+
+}
+
+func TestPollStrategyPerCall(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `{"id":"abc","status":"Pending","progress":0.1}`)
+	}))
+	defer srv.Close()
+	client := bfl.NewClientWithOptions("key", srv.URL, bfl.WithPollStrategy(bfl.FixedPolling(time.Millisecond)))
+	ar := &bfl.AsyncResponse{ID: "abc", PollingURL: srv.URL + "/v1/get_result?id=abc"}
+
+	// Per-call options take precedence over the client's.
+	_, err := bfl.Poll[*bfl.GenerateResult, *bfl.GenerateDetails](context.Background(), client, ar,
+		bfl.PollWithStrategy(bfl.AdaptivePolling(time.Millisecond, time.Second)),
+		bfl.PollWithMaxWait(100*time.Millisecond))
+	if !errors.Is(err, bfl.ErrMaxWaitExceeded) {
+		t.Fatalf("expected ErrMaxWaitExceeded, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected a single poll, got %d calls", calls)
+	}
+}
+
+func TestPollStrategies(t *testing.T) {
+	exp := bfl.ExponentialPolling(time.Second, 5*time.Second)
+	for attempt, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second} {
+		if got := exp.Next(bfl.PollState{Attempt: attempt + 1}); got != want {
+			t.Errorf("exponential attempt %d: expected %v, got %v", attempt+1, want, got)
+		}
+	}
+	adaptive := bfl.AdaptivePolling(time.Second, 5*time.Second)
+	if got := adaptive.Next(bfl.PollState{Progress: 0}); got != 5*time.Second {
+		t.Errorf("adaptive at no progress: expected 5s, got %v", got)
+	}
+	if got := adaptive.Next(bfl.PollState{Progress: 0.75}); got != 2*time.Second {
+		t.Errorf("adaptive at 75%% progress: expected 2s, got %v", got)
+	}
+}
+
+func TestPollStrategyZeroInterval(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		fmt.Fprint(w, `{"id":"abc","status":"Pending"}`)
+	}))
+	defer srv.Close()
+	client := bfl.NewClient("key", srv.URL)
+	ar := &bfl.AsyncResponse{ID: "abc", PollingURL: srv.URL + "/v1/get_result?id=abc"}
+
+	// Strategies returning no delay must not make Poll hammer the API.
+	for _, strategy := range []bfl.PollStrategy{&bfl.FixedPollStrategy{}, &bfl.ExponentialPollStrategy{}, &bfl.AdaptivePollStrategy{}} {
+		calls.Store(0)
+		_, err := bfl.Poll[*bfl.GenerateResult, *bfl.GenerateDetails](context.Background(), client, ar,
+			bfl.PollWithStrategy(strategy), bfl.PollWithMaxWait(100*time.Millisecond))
+		if !errors.Is(err, bfl.ErrMaxWaitExceeded) {
+			t.Fatalf("%T: expected ErrMaxWaitExceeded, got %v", strategy, err)
+		}
+		if n := calls.Load(); n != 1 {
+			t.Errorf("%T: expected a single poll, got %d calls", strategy, n)
+		}
+	}
+
+	inverted := bfl.AdaptivePolling(5*time.Second, time.Second)
+	if got := inverted.Next(bfl.PollState{Progress: 0}); got != 5*time.Second {
+		t.Errorf("adaptive with min above max: expected 5s, got %v", got)
+	}
+}
+
+func TestTaskHandle(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			fmt.Fprint(w, `{"id":"abc","polling_url":"http://`+r.Host+`/v1/get_result?id=abc"}`)
+			return
+		}
+		fmt.Fprint(w, `{"id":"abc","status":"Ready","result":{"sample":"https://example.com/a.jpg"}}`)
+	}))
+	defer srv.Close()
+	client := bfl.NewClient("key", srv.URL)
+	task, err := client.SubmitGenerate(context.Background(), testTask())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.ID() != "abc" {
+		t.Errorf("unexpected task id %q", task.ID())
+	}
+	select {
+	case <-task.Done():
+	case <-time.After(time.Second):
+		t.Fatal("task did not finish")
+	}
+	res, err := task.Wait(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Result.SampleURL != "https://example.com/a.jpg" {
+		t.Errorf("unexpected sample url %q", res.Result.SampleURL)
+	}
+	status, err := task.Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != bfl.StatusReady {
+		t.Errorf("unexpected status %q", status.Status)
+	}
+}
+
+func TestMinCredits(t *testing.T) {
+	var submissions atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/credits":
+			fmt.Fprint(w, `{"credits":4.5}`)
+		default:
+			submissions.Add(1)
+			fmt.Fprint(w, `{"id":"abc","polling_url":"unused"}`)
+		}
+	}))
+	defer srv.Close()
+
+	credits, err := bfl.NewClient("key", srv.URL).Credits(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if credits != 4.5 {
+		t.Errorf("unexpected credits %v", credits)
+	}
+
+	client := bfl.NewClientWithOptions("key", srv.URL, bfl.WithMinCredits(10))
+	_, err = client.AsyncRequest(context.Background(), testTask())
+	var creditsErr *bfl.InsufficientCreditsError
+	if !errors.As(err, &creditsErr) || !errors.Is(err, bfl.ErrInsufficientCredits) {
+		t.Fatalf("expected an insufficient credits error, got %v", err)
+	}
+	if creditsErr.Balance != 4.5 || creditsErr.Threshold != 10 {
+		t.Errorf("unexpected error fields %+v", creditsErr)
+	}
+	if n := submissions.Load(); n != 0 {
+		t.Errorf("expected no submissions, got %d", n)
+	}
+
+	client = bfl.NewClientWithOptions("key", srv.URL, bfl.WithMinCredits(1))
+	if _, err := client.AsyncRequest(context.Background(), testTask()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMinCreditsRefreshedAfterSubmission(t *testing.T) {
+	var balance atomic.Int32
+	balance.Store(3)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/credits":
+			fmt.Fprintf(w, `{"credits":%d}`, balance.Load())
+		default:
+			balance.Add(-1)
+			fmt.Fprint(w, `{"id":"abc","polling_url":"unused"}`)
+		}
+	}))
+	defer srv.Close()
+
+	// Every submission spends a credit, so the third one must be refused on a fresh balance.
+	client := bfl.NewClientWithOptions("key", srv.URL, bfl.WithMinCredits(2))
+	for i := 0; i < 2; i++ {
+		if _, err := client.AsyncRequest(context.Background(), testTask()); err != nil {
+			t.Fatalf("submission %d: %v", i+1, err)
+		}
+	}
+	if _, err := client.AsyncRequest(context.Background(), testTask()); !errors.Is(err, bfl.ErrInsufficientCredits) {
+		t.Fatalf("expected an insufficient credits error, got %v", err)
+	}
+	if n := balance.Load(); n != 1 {
+		t.Errorf("expected 2 submissions, balance is %d", n)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer srv.Close()
+	client := bfl.NewClient("key", srv.URL)
+	task := &bfl.FluxDevGenerate{Prompt: "test", Width: 1000, Height: 768, Guidance: 7, SafetyTolerance: bfl.Ptr(9)}
+	_, err := client.AsyncRequest(context.Background(), task)
+	var validationErr *bfl.TaskValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a task validation error, got %v", err)
+	}
+	var httpErr *bfl.HTTPValidationError
+	if errors.As(err, &httpErr) {
+		t.Errorf("expected a local failure to be distinguishable from an API response, got %v", err)
+	}
+	fields := make(map[string]bool)
+	for _, detail := range validationErr.Detail {
+		fields[detail.Loc[1].(string)] = true
+	}
+	for _, field := range []string{"width", "guidance", "safety_tolerance"} {
+		if !fields[field] {
+			t.Errorf("expected an error for %s, got %v", field, validationErr.Detail)
+		}
+	}
+	if calls != 0 {
+		t.Errorf("expected no request to be sent, got %d", calls)
+	}
+}
+
+func TestTaskValidationErrorWithoutLocation(t *testing.T) {
+	err := &bfl.TaskValidationError{Detail: []bfl.ValidationError{{Msg: "bad"}}}
+	if got := err.Error(); got != "Invalid task (1/1): bad" {
+		t.Errorf("unexpected message %q", got)
+	}
+}
@@ -0,0 +1,63 @@
+package canny
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/Kodlak15/bfl-go/bfl"
+	"github.com/Kodlak15/bfl-go/canny"
+)
+
+// An image that is black on the left half and white on the right half.
+func step() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 100, 60))
+	for y := 0; y < 60; y++ {
+		for x := 50; x < 100; x++ {
+			img.SetGray(x, y, color.Gray{Y: 255})
+		}
+	}
+	return img
+}
+
+func TestDetect(t *testing.T) {
+	edges := canny.Detect(step(), canny.DefaultLowThreshold, canny.DefaultHighThreshold)
+	for y := 1; y < 59; y++ {
+		count := 0
+		for x := 0; x < 100; x++ {
+			if edges.GrayAt(x, y).Y == 255 {
+				count++
+				if x < 48 || x > 51 {
+					t.Fatalf("unexpected edge at (%d, %d)", x, y)
+				}
+			}
+		}
+		if count != 1 {
+			t.Fatalf("expected a thin edge on row %d, got %d pixels", y, count)
+		}
+	}
+
+	// The gradient magnitude of the step is 4*255, so a higher threshold finds nothing.
+	edges = canny.Detect(step(), 1100, 1200)
+	for _, v := range edges.Pix {
+		if v != 0 {
+			t.Fatal("expected no edges above the gradient magnitude")
+		}
+	}
+}
+
+func TestPreprocess(t *testing.T) {
+	task := bfl.NewFluxProCannyGenerate("test", "")
+	if _, err := canny.Preprocess(task, step()); err != nil {
+		t.Fatal(err)
+	}
+	if task.PreprocessedImage == "" {
+		t.Error("expected the preprocessed image to be set")
+	}
+	if err := task.Validate(); err != nil {
+		t.Error(err)
+	}
+	if _, err := canny.Preprocess(bfl.NewFluxDevGenerate("test"), step()); err == nil {
+		t.Error("expected an error for a task without Canny preprocessing")
+	}
+}
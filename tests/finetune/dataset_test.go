@@ -0,0 +1,114 @@
+package bfl
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Kodlak15/bfl-go/bfl"
+)
+
+func TestDatasetFromDir(t *testing.T) {
+	dir := t.TempDir()
+	image, err := os.ReadFile("../../assets/test-finetune-images/carrot-guy.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{
+		"carrot-guy.jpg": image,
+		"carrot-guy.txt": []byte("a photo of TOK"),
+		"second.JPG":     image,
+		"orphan.txt":     []byte("no image"),
+		"notes.md":       []byte("ignored"),
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dataset, err := bfl.NewDatasetFromDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dataset.Images) != 2 || len(dataset.OrphanCaptions) != 1 {
+		t.Fatalf("unexpected dataset %+v", dataset)
+	}
+
+	task := &bfl.FluxFinetune{}
+	if err := dataset.Apply(task); err != nil {
+		t.Fatal(err)
+	}
+	data, err := base64.StdEncoding.DecodeString(task.FileData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range r.File {
+		names = append(names, f.Name)
+	}
+	want := []string{"carrot-guy.jpg", "carrot-guy.txt", "second.jpg"}
+	if len(names) != len(want) {
+		t.Fatalf("unexpected archive contents %v", names)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("unexpected archive contents %v", names)
+			break
+		}
+	}
+}
+
+func TestDatasetLint(t *testing.T) {
+	dir := t.TempDir()
+	image, err := os.ReadFile("../../assets/test-finetune-images/carrot-guy.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{
+		"a.jpg":      image,
+		"a.txt":      []byte("a photo of a carrot"),
+		"b.jpg":      image,
+		"b.txt":      []byte("a photo of TOK"),
+		"broken.png": []byte("not an image"),
+		"corrupt.webp": []byte("RIFF\x24\x00\x00\x00WEBPVP8 \x18\x00\x00\x00" +
+			"\x00\x00\x00\x9d\x01\x2a\x00\x04\x00\x03truncated"),
+		"orphan.txt": []byte("TOK"),
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dataset, err := bfl.NewDatasetFromDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := dataset.LintFor(&bfl.FluxFinetune{TriggerWord: "TOK"}, bfl.LintOptions{MinImages: 2, MaxArchiveBytes: 2 * len(image)})
+	codes := make(map[string]bfl.LintSeverity)
+	for _, issue := range report.Issues {
+		codes[issue.Code] = issue.Severity
+	}
+	want := map[string]bfl.LintSeverity{
+		bfl.LintUnsupportedFormat:  bfl.LintError,
+		bfl.LintCorruptImage:       bfl.LintError,
+		bfl.LintArchiveTooLarge:    bfl.LintError,
+		bfl.LintDuplicateImage:     bfl.LintWarning,
+		bfl.LintOrphanCaption:      bfl.LintWarning,
+		bfl.LintMissingTriggerWord: bfl.LintWarning,
+	}
+	for code, severity := range want {
+		if codes[code] != severity {
+			t.Errorf("expected %s %s, got issues %+v", severity, code, report.Issues)
+		}
+	}
+	if !report.HasErrors() {
+		t.Error("expected the report to have errors")
+	}
+}
@@ -3,7 +3,11 @@ package bfl
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
+	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
@@ -30,7 +34,7 @@ func TestFinetune(t *testing.T) {
 		Mode:            bfl.FinetuneModeGeneral,
 		Iterations:      100,
 		LearningRate:    0.003,
-		Captioning:      true,
+		Captioning:      bfl.Ptr(true),
 		Priority:        bfl.FinetunePriorityQuality,
 		FinetuneType:    bfl.FinetuneTypeFull,
 		LoraRank:        32,
@@ -39,9 +43,72 @@ func TestFinetune(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create async request: %v", err)
 	}
-	resultResponse, err := bfl.Poll[*bfl.FinetuneResult, *bfl.FinetuneDetails](context.Background(), client, ar, true)
+	resultResponse, err := bfl.Poll[*bfl.FinetuneResult, *bfl.FinetuneDetails](context.Background(), client, ar)
 	if err != nil {
 		t.Fatalf("Failed to poll result: %v", err)
 	}
 	t.Logf("Finetune result: %+v", resultResponse)
 }
+
+func TestFinetuneDetailsUnmarshal(t *testing.T) {
+	data := []byte(`{"id":"ft-1","status":"Ready","result":{"finetune_id":"ft-1"},"details":{"trigger_word":"TOK","mode":"character","iterations":300,"lora_rank":32,"dataset_size":12}}`)
+	var res bfl.ResultResponse[*bfl.FinetuneResult, *bfl.FinetuneDetails]
+	if err := json.Unmarshal(data, &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Result.FinetuneID != "ft-1" {
+		t.Errorf("unexpected finetune id %q", res.Result.FinetuneID)
+	}
+	d := res.Details
+	if d.TriggerWord != "TOK" || d.Mode != bfl.FinetuneModeCharacter || d.Iterations != 300 || d.LoraRank != bfl.LoraRank32 {
+		t.Errorf("unexpected details %+v", d)
+	}
+	if string(d.Extra["dataset_size"]) != "12" || len(d.Extra) != 1 {
+		t.Errorf("unexpected extra fields %v", d.Extra)
+	}
+}
+
+func TestFinetuneManagement(t *testing.T) {
+	deleted := ""
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/my_finetunes":
+			fmt.Fprint(w, `{"finetunes":["ft-1","ft-2"]}`)
+		case "/v1/finetune_details":
+			fmt.Fprintf(w, `{"finetune_details":{"trigger_word":"TOK","finetune_comment":%q}}`, r.URL.Query().Get("finetune_id"))
+		case "/v1/delete_finetune":
+			var body struct {
+				FinetuneID string `json:"finetune_id"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			deleted = body.FinetuneID
+			fmt.Fprint(w, `{}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	client := bfl.NewClient("key", srv.URL)
+	ctx := context.Background()
+
+	ids, err := client.ListFinetunes(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0] != "ft-1" {
+		t.Errorf("unexpected finetunes %v", ids)
+	}
+	details, err := client.GetFinetuneDetails(ctx, "ft-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if details.FinetuneID != "ft-1" || details.FinetuneComment != "ft-1" || details.TriggerWord != "TOK" {
+		t.Errorf("unexpected details %+v", details)
+	}
+	if err := client.DeleteFinetune(ctx, "ft-2"); err != nil {
+		t.Fatal(err)
+	}
+	if deleted != "ft-2" {
+		t.Errorf("unexpected deleted finetune %q", deleted)
+	}
+}
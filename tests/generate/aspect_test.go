@@ -0,0 +1,92 @@
+package bfl
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Kodlak15/bfl-go/bfl"
+)
+
+func TestParseAspectRatio(t *testing.T) {
+	a, err := bfl.ParseAspectRatio(" 16 : 9 ")
+	if err != nil || a != bfl.AspectRatio16x9 {
+		t.Fatalf("got %q, %v", a, err)
+	}
+	for _, s := range []string{"", "16", "16:0", "-4:3", "a:b", "16x9"} {
+		if _, err := bfl.ParseAspectRatio(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+
+	if err := bfl.AspectRatio21x9.Validate(); err != nil {
+		t.Errorf("21:9: %v", err)
+	}
+	if err := bfl.AspectRatio("22:9").Validate(); err == nil {
+		t.Error("expected 22:9 to be out of range")
+	}
+	if err := bfl.AspectRatio("1:3").Validate(); err == nil {
+		t.Error("expected 1:3 to be out of range")
+	}
+}
+
+func TestAspectRatioDimensions(t *testing.T) {
+	cases := []struct {
+		ratio bfl.AspectRatio
+		mp    float64
+		w, h  int
+	}{
+		{bfl.AspectRatio1x1, 1, 992, 992},
+		{bfl.AspectRatio16x9, 1, 1312, 736},
+		{bfl.AspectRatio9x16, 1, 736, 1312},
+		{bfl.AspectRatio4x3, 0.75, 1024, 768},
+		{bfl.AspectRatio1x1, 10, 1440, 1440},
+		{bfl.AspectRatio1x1, 0.01, 256, 256},
+	}
+	for _, c := range cases {
+		w, h, err := c.ratio.Dimensions(c.mp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w != c.w || h != c.h {
+			t.Errorf("%s at %vMP: got %dx%d, want %dx%d", c.ratio, c.mp, w, h, c.w, c.h)
+		}
+	}
+	if _, _, err := bfl.AspectRatio1x1.Dimensions(0); err == nil {
+		t.Error("expected error for zero megapixels")
+	}
+}
+
+func TestApplyAspectRatio(t *testing.T) {
+	dev := bfl.NewFluxDevGenerate("a lighthouse")
+	if err := bfl.ApplyAspectRatio(dev, bfl.AspectRatio16x9, 1); err != nil {
+		t.Fatal(err)
+	}
+	if dev.Width != 1312 || dev.Height != 736 {
+		t.Errorf("got %dx%d", dev.Width, dev.Height)
+	}
+	if err := dev.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	ultra := bfl.NewFluxPro11UltraGenerate("a lighthouse")
+	if err := bfl.ApplyAspectRatio(ultra, bfl.AspectRatio9x21, 1); err != nil {
+		t.Fatal(err)
+	}
+	body, _ := json.Marshal(ultra)
+	if !strings.Contains(string(body), `"aspect_ratio":"9:21"`) {
+		t.Errorf("unexpected body %s", body)
+	}
+	if err := bfl.ApplyAspectRatio(ultra, "3:1", 1); err == nil {
+		t.Error("expected error for 3:1 on ultra")
+	}
+
+	ultra.AspectRatio = "3:1"
+	if err := ultra.Validate(); err == nil {
+		t.Error("expected validation error")
+	}
+
+	if err := bfl.ApplyAspectRatio(&bfl.FluxProFillGenerate{}, bfl.AspectRatio1x1, 1); err == nil {
+		t.Error("expected error for fill task")
+	}
+}
@@ -2,6 +2,7 @@ package bfl
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"testing"
 
@@ -17,10 +18,10 @@ func TestGenerateDev(t *testing.T) {
 		Width:            1024,
 		Height:           768,
 		Steps:            28,
-		PromptUpsampling: false,
-		Seed:             42,
+		PromptUpsampling: bfl.Ptr(false),
+		Seed:             bfl.Ptr(42),
 		Guidance:         3,
-		SafetyTolerance:  2,
+		SafetyTolerance:  bfl.Ptr(2),
 		OutputFormat:     "jpeg",
 	}
 	result, err := bfl.Generate(context.Background(), client, task)
@@ -29,3 +30,63 @@ func TestGenerateDev(t *testing.T) {
 	}
 	t.Log(result.SampleURL)
 }
+
+func TestGenerateDetailsUnmarshal(t *testing.T) {
+	data := []byte(`{"id":"abc","status":"Content Moderated","details":{"Moderation Reasons":["Derivative Works Filter"],"region":"eu"},"beta_field":true}`)
+	var res bfl.ResultResponse[*bfl.GenerateResult, *bfl.GenerateDetails]
+	if err := json.Unmarshal(data, &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Details.ModerationReasons) != 1 || res.Details.ModerationReasons[0] != "Derivative Works Filter" {
+		t.Errorf("unexpected moderation reasons %v", res.Details.ModerationReasons)
+	}
+	if string(res.Details.Extra["region"]) != `"eu"` {
+		t.Errorf("unexpected extra fields %v", res.Details.Extra)
+	}
+	if string(res.Raw) != string(data) {
+		t.Errorf("raw response not preserved: %s", res.Raw)
+	}
+}
+
+func TestOptionalFields(t *testing.T) {
+	data, err := json.Marshal(&bfl.FluxPro11UltraGenerate{Prompt: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"prompt":"test"}` {
+		t.Errorf("unset fields must not be sent, got %s", data)
+	}
+	data, err = json.Marshal(&bfl.FluxPro11UltraGenerate{Prompt: "test", Seed: bfl.Ptr(0), SafetyTolerance: bfl.Ptr(0), Raw: bfl.Ptr(false)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"prompt":"test","seed":0,"safety_tolerance":0,"raw":false}` {
+		t.Errorf("zero values that are set must be sent, got %s", data)
+	}
+}
+
+func TestConstructorDefaults(t *testing.T) {
+	tasks := []bfl.GenerateTask{
+		bfl.NewFluxPro11Generate("test"),
+		bfl.NewFluxProGenerate("test"),
+		bfl.NewFluxDevGenerate("test"),
+		bfl.NewFluxPro11UltraGenerate("test"),
+		bfl.NewFluxProFillGenerate("aW1hZ2U="),
+		bfl.NewFluxProCannyGenerate("test", "aW1hZ2U="),
+		bfl.NewFluxProDepthGenerate("test", "aW1hZ2U="),
+		bfl.NewFluxProFinetunedGenerate("ft-1", "test"),
+		bfl.NewFluxProDepthFinetunedGenerate("ft-1", "test", "aW1hZ2U="),
+		bfl.NewFluxProCannyFinetunedGenerate("ft-1", "test", "aW1hZ2U="),
+		bfl.NewFluxProFillFinetunedGenerate("ft-1", "aW1hZ2U="),
+		bfl.NewFluxPro11UltraFinetunedGenerate("ft-1", "test"),
+	}
+	for _, task := range tasks {
+		if err := task.(interface{ Validate() error }).Validate(); err != nil {
+			t.Errorf("%T: defaults are not valid: %v", task, err)
+		}
+	}
+	dev := bfl.NewFluxDevGenerate("test")
+	if dev.Steps != 28 || dev.Guidance != 3 || *dev.SafetyTolerance != 2 {
+		t.Errorf("unexpected defaults %+v", dev)
+	}
+}
@@ -0,0 +1,96 @@
+package bfl
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/Kodlak15/bfl-go/bfl"
+)
+
+func TestImageInput(t *testing.T) {
+	ctx := context.Background()
+
+	// Images that already satisfy the options are passed through.
+	original, err := os.ReadFile("../../assets/test-finetune-images/carrot-guy.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := bfl.ImageFromPath("../../assets/test-finetune-images/carrot-guy.jpg").Base64(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded != base64.StdEncoding.EncodeToString(original) {
+		t.Error("expected the image to be passed through unchanged")
+	}
+
+	// Downsizing keeps the aspect ratio.
+	data, err := bfl.ImageFromBytes(original).Encode(ctx, nil, bfl.ImageMaxSide(64), bfl.ImageFormat("png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, _, _ := image.DecodeConfig(bytes.NewReader(original))
+	if format != "png" || max(cfg.Width, cfg.Height) != 64 || cfg.Width*src.Height/src.Width != cfg.Height {
+		t.Errorf("unexpected %s image of %dx%d from %dx%d", format, cfg.Width, cfg.Height, src.Width, src.Height)
+	}
+
+	// Decoded images with transparency are encoded as PNG.
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	img.Set(0, 0, color.NRGBA{A: 128})
+	data, err = bfl.ImageFromImage(img).Encode(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, format, _ := image.DecodeConfig(bytes.NewReader(data)); format != "png" {
+		t.Errorf("expected png, got %s", format)
+	}
+
+	// Images can be fetched from URLs.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		png.Encode(w, img)
+	}))
+	defer srv.Close()
+	fetched, err := bfl.ImageFromURL(srv.URL).Image(ctx, bfl.NewClient("key", srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fetched.Bounds().Dx() != 8 {
+		t.Errorf("unexpected bounds %v", fetched.Bounds())
+	}
+}
+
+func TestImageInputFormats(t *testing.T) {
+	ctx := context.Background()
+	img := image.NewPaletted(image.Rect(0, 0, 2000, 1000), color.Palette{color.White, color.Black})
+	var buf bytes.Buffer
+	if err := gif.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := bfl.ImageFromBytes(buf.Bytes()).Encode(ctx, nil, bfl.ImageAPILimits())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "jpeg" && format != "png" {
+		t.Errorf("expected a GIF to be converted, got %s", format)
+	}
+	if cfg.Width != 1440 || cfg.Height != 720 {
+		t.Errorf("expected the image to be downsized to 1440x720, got %dx%d", cfg.Width, cfg.Height)
+	}
+}
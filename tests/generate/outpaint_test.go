@@ -0,0 +1,86 @@
+package bfl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Kodlak15/bfl-go/bfl"
+)
+
+func TestPrepareOutpaint(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	canvas, m, err := bfl.PrepareOutpaint(img, bfl.OutpaintOptions{AspectRatio: "16:9", Sides: bfl.SideRight})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := canvas.Bounds(); b.Dx() != 178 || b.Dy() != 100 {
+		t.Fatalf("unexpected canvas size %v", b)
+	}
+	if m.Bounds() != canvas.Bounds() {
+		t.Fatalf("mask size %v does not match canvas %v", m.Bounds(), canvas.Bounds())
+	}
+	if m.Image().GrayAt(99, 50).Y != 0 || m.Image().GrayAt(100, 50).Y != 255 {
+		t.Error("expected only the added area to be selected")
+	}
+
+	_, m, err = bfl.PrepareOutpaint(img, bfl.OutpaintOptions{Top: 10, Left: 20, Overlap: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := m.Bounds(); b.Dx() != 120 || b.Dy() != 110 {
+		t.Fatalf("unexpected mask size %v", b)
+	}
+	if m.Image().GrayAt(22, 50).Y != 255 || m.Image().GrayAt(25, 50).Y != 0 {
+		t.Error("expected the overlap to be selected")
+	}
+
+	if _, _, err := bfl.PrepareOutpaint(img, bfl.OutpaintOptions{Width: 50}); err == nil {
+		t.Error("expected an error for a target smaller than the image")
+	}
+	if _, _, err := bfl.PrepareOutpaint(img, bfl.OutpaintOptions{Left: 10, Right: 10, Overlap: 50}); err == nil {
+		t.Error("expected an error for an overlap covering the whole image")
+	}
+}
+
+func TestOutpaint(t *testing.T) {
+	var submitted bfl.FluxProFillGenerate
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/flux-pro-1.0-fill":
+			json.NewDecoder(r.Body).Decode(&submitted)
+			fmt.Fprintf(w, `{"id":"abc","polling_url":"http://%s/v1/get_result?id=abc"}`, r.Host)
+		case "/v1/get_result":
+			fmt.Fprintf(w, `{"id":"abc","status":"Ready","result":{"sample":"http://%s/sample.png"}}`, r.Host)
+		case "/sample.png":
+			png.Encode(w, image.NewRGBA(image.Rect(0, 0, 120, 100)))
+		}
+	}))
+	defer srv.Close()
+	client := bfl.NewClient("key", srv.URL)
+	task := bfl.NewFluxProFillGenerate("")
+	task.Prompt = "a wider landscape"
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	extended, result, err := bfl.Outpaint(context.Background(), client, img, bfl.OutpaintOptions{Width: 120, Task: task})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if extended.Bounds().Dx() != 120 || result.SampleURL == "" {
+		t.Errorf("unexpected outpainting result %v %+v", extended.Bounds(), result)
+	}
+	if submitted.Prompt != "a wider landscape" || submitted.Image == "" || submitted.Mask == "" {
+		t.Errorf("unexpected submitted task %+v", submitted)
+	}
+	if task.Image != "" {
+		t.Error("the task passed in the options must not be modified")
+	}
+
+	if _, _, err := bfl.Outpaint(context.Background(), client, img, bfl.OutpaintOptions{Width: 1500}); err == nil {
+		t.Error("expected an error for an extended image larger than the API supports")
+	}
+}
@@ -0,0 +1,72 @@
+package bfl
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/Kodlak15/bfl-go/bfl"
+)
+
+func TestSampleDownload(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 3))
+	img.Set(1, 1, color.RGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Key") != "" {
+			t.Error("the API key must not be sent to sample URLs")
+		}
+		w.Write(buf.Bytes())
+	}))
+	defer srv.Close()
+	client := bfl.NewClient("key", srv.URL)
+	result := &bfl.GenerateResult{SampleURL: srv.URL + "/sample.png?sig=abc"}
+	sum := sha256.Sum256(buf.Bytes())
+
+	sample, err := result.Download(context.Background(), client, bfl.DownloadChecksum(hex.EncodeToString(sum[:])))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sample.Format != "png" {
+		t.Errorf("unexpected format %q", sample.Format)
+	}
+	decoded, err := sample.Image()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Bounds() != img.Bounds() {
+		t.Errorf("unexpected bounds %v", decoded.Bounds())
+	}
+	path, err := sample.Save(filepath.Join(t.TempDir(), "render"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Ext(path) != ".png" {
+		t.Errorf("unexpected path %q", path)
+	}
+	path, err = sample.Save(filepath.Join(t.TempDir(), "render.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != "render.png" {
+		t.Errorf("expected a mismatched extension to be replaced, got %q", path)
+	}
+
+	if _, err := result.Download(context.Background(), client, bfl.DownloadMaxBytes(10)); err == nil {
+		t.Error("expected the size limit to be enforced")
+	}
+	if _, err := result.Download(context.Background(), client, bfl.DownloadChecksum("00")); err == nil {
+		t.Error("expected the checksum to be verified")
+	}
+}
@@ -0,0 +1,108 @@
+package mask
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/Kodlak15/bfl-go/bfl"
+	"github.com/Kodlak15/bfl-go/mask"
+)
+
+func TestShapes(t *testing.T) {
+	m := mask.New(100, 100).
+		Rect(image.Rect(0, 0, 10, 10)).
+		Ellipse(image.Pt(50, 50), 10, 5).
+		Polygon(image.Pt(70, 70), image.Pt(90, 70), image.Pt(90, 90))
+	img := m.Image()
+	selected := []image.Point{{5, 5}, {50, 50}, {59, 50}, {50, 54}, {89, 71}}
+	kept := []image.Point{{15, 15}, {50, 57}, {61, 50}, {71, 89}}
+	for _, p := range selected {
+		if img.GrayAt(p.X, p.Y).Y != 255 {
+			t.Errorf("expected %v to be selected", p)
+		}
+	}
+	for _, p := range kept {
+		if img.GrayAt(p.X, p.Y).Y != 0 {
+			t.Errorf("expected %v to be kept", p)
+		}
+	}
+	m.Invert()
+	if img := m.Image(); img.GrayAt(5, 5).Y != 0 || img.GrayAt(15, 15).Y != 255 {
+		t.Error("expected the selection to be inverted")
+	}
+}
+
+func TestFeather(t *testing.T) {
+	m := mask.New(40, 40).Rect(image.Rect(20, 0, 40, 40)).Feather(4)
+	img := m.Image()
+	if v := img.GrayAt(19, 20).Y; v == 0 || v == 255 {
+		t.Errorf("expected a soft edge, got %d", v)
+	}
+	if img.GrayAt(0, 20).Y != 0 || img.GrayAt(39, 20).Y != 255 {
+		t.Error("expected areas far from the edge to be unchanged")
+	}
+}
+
+func TestFromAlphaAndValidate(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	for i := range src.Pix {
+		src.Pix[i] = 255
+	}
+	src.SetNRGBA(1, 1, color.NRGBA{})
+	m := mask.FromAlpha(src)
+	if m.Image().GrayAt(1, 1).Y != 255 || m.Image().GrayAt(0, 0).Y != 0 {
+		t.Error("expected transparent pixels to be selected")
+	}
+	if err := mask.Validate(src, m.Image()); err != nil {
+		t.Error(err)
+	}
+	if err := mask.Validate(src, mask.New(5, 4).Image()); err == nil {
+		t.Error("expected a size mismatch to be reported")
+	}
+	colored := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	colored.Set(2, 2, color.RGBA{R: 255, A: 255})
+	if err := mask.Validate(src, colored); err == nil {
+		t.Error("expected a colored mask to be reported")
+	}
+	if err := mask.Validate(src, mask.New(4, 4).Image()); err == nil {
+		t.Error("expected an entirely black mask to be reported")
+	}
+
+	encoded, err := m.Base64()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mask.ValidateBase64(encoded, encoded); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFillValidatesMask(t *testing.T) {
+	src := image.NewGray(image.Rect(0, 0, 8, 8))
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+	img := base64.StdEncoding.EncodeToString(buf.Bytes())
+	m, err := mask.New(4, 4).Rect(image.Rect(0, 0, 2, 2)).Base64()
+	if err != nil {
+		t.Fatal(err)
+	}
+	task := bfl.NewFluxProFillGenerate(img)
+	task.Mask = m
+	var validationErr *bfl.TaskValidationError
+	if err := task.Validate(); !errors.As(err, &validationErr) || validationErr.Detail[0].Loc[1] != "mask" {
+		t.Fatalf("expected the mismatched mask to be reported, got %v", err)
+	}
+	if task.Mask, err = mask.ForImage(src).Rect(image.Rect(0, 0, 2, 2)).Base64(); err != nil {
+		t.Fatal(err)
+	}
+	if err := task.Validate(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,210 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Kodlak15/bfl-go/bfl"
+	"github.com/Kodlak15/bfl-go/webhook"
+)
+
+func deliver(h http.Handler, header http.Header, body []byte) int {
+	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(body))
+	req.Header = header
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestHandlerDispatchesGeneration(t *testing.T) {
+	var received *webhook.GenerateResponse
+	h := webhook.NewHandler(webhook.HMACVerifier("secret", 0))
+	h.OnGenerate = func(ctx context.Context, res *webhook.GenerateResponse) error {
+		received = res
+		return nil
+	}
+	body := []byte(`{"id":"abc","status":"Ready","result":{"sample":"https://example.com/a.jpg"}}`)
+	header := webhook.SignedHeader("secret", time.Now(), body)
+	if code := deliver(h, header, body); code != http.StatusNoContent {
+		t.Fatalf("unexpected status code %d", code)
+	}
+	if received == nil || received.ID != "abc" || received.Result.SampleURL != "https://example.com/a.jpg" {
+		t.Fatalf("unexpected delivery %+v", received)
+	}
+
+	// The same delivery must not be accepted twice.
+	if code := deliver(h, header, body); code != http.StatusUnauthorized {
+		t.Errorf("expected a replay to be rejected, got %d", code)
+	}
+}
+
+func TestHandlerRejectsInvalidDeliveries(t *testing.T) {
+	h := webhook.NewHandler(webhook.HMACVerifier("secret", 0))
+	body := []byte(`{"id":"abc","status":"Ready","result":{"sample":"https://example.com/a.jpg"}}`)
+	cases := map[string]http.Header{
+		"unsigned":    {},
+		"wrong key":   webhook.SignedHeader("other", time.Now(), body),
+		"stale":       webhook.SignedHeader("secret", time.Now().Add(-time.Hour), body),
+		"from future": webhook.SignedHeader("secret", time.Now().Add(time.Hour), body),
+	}
+	for name, header := range cases {
+		if code := deliver(h, header, body); code != http.StatusUnauthorized {
+			t.Errorf("%s: expected rejection, got %d", name, code)
+		}
+	}
+}
+
+func TestHandlerAcceptsRedeliveryAfterCallbackFailure(t *testing.T) {
+	calls := 0
+	h := webhook.NewHandler(webhook.HMACVerifier("secret", 0))
+	h.OnGenerate = func(ctx context.Context, res *webhook.GenerateResponse) error {
+		calls++
+		if calls == 1 {
+			return errors.New("database unavailable")
+		}
+		return nil
+	}
+	body := []byte(`{"id":"abc","status":"Ready","result":{"sample":"https://example.com/a.jpg"}}`)
+	header := webhook.SignedHeader("secret", time.Now(), body)
+	if code := deliver(h, header, body); code != http.StatusInternalServerError {
+		t.Fatalf("expected the failed callback to be reported, got %d", code)
+	}
+	if code := deliver(h, header, body); code != http.StatusNoContent {
+		t.Fatalf("expected the redelivery to be accepted, got %d", code)
+	}
+	if code := deliver(h, header, body); code != http.StatusUnauthorized {
+		t.Errorf("expected a replay of a handled delivery to be rejected, got %d", code)
+	}
+}
+
+func TestHandlerWithoutVerifier(t *testing.T) {
+	body := []byte(`{"id":"abc","status":"Ready","result":{"sample":"https://example.com/a.jpg"}}`)
+	h := webhook.NewHandler(nil)
+	if err := h.Verify(http.Header{}, body); !errors.Is(err, webhook.ErrNoVerifier) {
+		t.Errorf("expected ErrNoVerifier, got %v", err)
+	}
+	if code := deliver(h, http.Header{}, body); code != http.StatusInternalServerError {
+		t.Errorf("expected an unsigned delivery to be rejected, got %d", code)
+	}
+
+	h.InsecureSkipVerify = true
+	if code := deliver(h, http.Header{}, body); code != http.StatusNoContent {
+		t.Errorf("expected verification to be skipped, got %d", code)
+	}
+}
+
+func TestHandlerCustomVerifier(t *testing.T) {
+	h := webhook.NewHandler(func(header http.Header, body []byte) error {
+		if header.Get("Authorization") != "Bearer token" {
+			return webhook.ErrInvalidSignature
+		}
+		return nil
+	})
+	body := []byte(`{"id":"abc","status":"Ready","result":{"sample":"https://example.com/a.jpg"}}`)
+	if code := deliver(h, http.Header{"Authorization": {"Bearer other"}}, body); code != http.StatusUnauthorized {
+		t.Errorf("expected the verifier to reject the delivery, got %d", code)
+	}
+	header := http.Header{"Authorization": {"Bearer token"}}
+	if code := deliver(h, header, body); code != http.StatusNoContent {
+		t.Errorf("expected the verifier to accept the delivery, got %d", code)
+	}
+	if code := deliver(h, header, body); code != http.StatusUnauthorized {
+		t.Errorf("expected a replay to be rejected, got %d", code)
+	}
+}
+
+func TestWaitCompletesOnDelivery(t *testing.T) {
+	registry := webhook.NewRegistry()
+	h := webhook.NewHandler(webhook.HMACVerifier("secret", 0))
+	h.Registry = registry
+	client := bfl.NewClient("key", "http://127.0.0.1:0")
+	ar := &bfl.AsyncResponse{ID: "abc", PollingURL: "http://127.0.0.1:0/v1/get_result?id=abc"}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		body := []byte(`{"id":"abc","status":"Ready","result":{"sample":"https://example.com/a.jpg"}}`)
+		deliver(h, webhook.SignedHeader("secret", time.Now(), body), body)
+	}()
+	res, err := webhook.WaitGenerate(context.Background(), registry, client, ar, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Result.SampleURL != "https://example.com/a.jpg" {
+		t.Errorf("unexpected sample url %q", res.Result.SampleURL)
+	}
+}
+
+func TestWaitFallsBackToPolling(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"abc","status":"Content Moderated"}`)
+	}))
+	defer srv.Close()
+	client := bfl.NewClient("key", srv.URL)
+	ar := &bfl.AsyncResponse{ID: "abc", PollingURL: srv.URL + "/v1/get_result?id=abc"}
+	_, err := webhook.WaitGenerate(context.Background(), webhook.NewRegistry(), client, ar, 10*time.Millisecond)
+	var moderationErr *bfl.ModerationError
+	if !errors.As(err, &moderationErr) {
+		t.Fatalf("expected a moderation error from polling, got %v", err)
+	}
+}
+
+func TestTrackWaitsForDeliveryAfterPollError(t *testing.T) {
+	var polls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			fmt.Fprintf(w, `{"id":"abc","polling_url":"http://%s/v1/get_result?id=abc"}`, r.Host)
+			return
+		}
+		polls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+	registry := webhook.NewRegistry()
+	h := webhook.NewHandler(webhook.HMACVerifier("secret", 0))
+	h.Registry = registry
+	client := bfl.NewClientWithOptions("key", srv.URL, bfl.WithMaxActiveTasks(1))
+	task, err := webhook.SubmitGenerate(context.Background(), registry, client, bfl.NewFluxDevGenerate("test"), 5*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer task.Stop()
+
+	// The task keeps its slot while the delivery is awaited, even though polling failed.
+	deadline := time.Now().Add(time.Second)
+	for polls.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := polls.Load(); n < 2 {
+		t.Fatalf("expected polling to be retried, got %d polls", n)
+	}
+	if n := client.ActiveTasks(); n != 1 {
+		t.Fatalf("expected the task to keep its slot, got %d active tasks", n)
+	}
+
+	body := []byte(`{"id":"abc","status":"Ready","result":{"sample":"https://example.com/a.jpg"}}`)
+	deliver(h, webhook.SignedHeader("secret", time.Now(), body), body)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	res, err := task.Wait(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Result.SampleURL != "https://example.com/a.jpg" {
+		t.Errorf("unexpected sample url %q", res.Result.SampleURL)
+	}
+	select {
+	case <-task.Done():
+	default:
+		t.Error("expected the task to be done")
+	}
+	if n := client.ActiveTasks(); n != 0 {
+		t.Errorf("expected the slot to be released once the delivery landed, got %d active tasks", n)
+	}
+}
@@ -0,0 +1,207 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Kodlak15/bfl-go/bfl"
+)
+
+// Pairs incoming webhook deliveries with tasks that are being waited on.
+//
+// Deliveries for tasks nobody waits on yet are retained for a while, so a webhook that lands
+// before Wait or Track is called is not lost.
+type Registry struct {
+	// How long deliveries are retained for tasks nobody is waiting on.
+	// Default: 10 minutes.
+	Retention time.Duration
+
+	mu      sync.Mutex
+	waiters map[string][]chan []byte
+	pending map[string]delivery
+}
+
+type delivery struct {
+	body       []byte
+	receivedAt time.Time
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) retention() time.Duration {
+	if r.Retention > 0 {
+		return r.Retention
+	}
+	return 10 * time.Minute
+}
+
+// Hand the body of a verified delivery for the given task to whoever waits on it.
+func (r *Registry) Deliver(taskID string, body []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if waiters := r.waiters[taskID]; len(waiters) > 0 {
+		for _, ch := range waiters {
+			ch <- body
+		}
+		delete(r.waiters, taskID)
+		return
+	}
+	if r.pending == nil {
+		r.pending = make(map[string]delivery)
+	}
+	now := time.Now()
+	for id, d := range r.pending {
+		if now.Sub(d.receivedAt) > r.retention() {
+			delete(r.pending, id)
+		}
+	}
+	r.pending[taskID] = delivery{body: body, receivedAt: now}
+}
+
+// Register interest in the delivery for a task. The returned function must be called once
+// the delivery is no longer needed.
+func (r *Registry) subscribe(taskID string) (<-chan []byte, func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ch := make(chan []byte, 1)
+	if d, ok := r.pending[taskID]; ok {
+		delete(r.pending, taskID)
+		ch <- d.body
+		return ch, func() {}
+	}
+	if r.waiters == nil {
+		r.waiters = make(map[string][]chan []byte)
+	}
+	r.waiters[taskID] = append(r.waiters[taskID], ch)
+	return ch, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		waiters := r.waiters[taskID]
+		for i, waiter := range waiters {
+			if waiter == ch {
+				r.waiters[taskID] = append(waiters[:i], waiters[i+1:]...)
+				break
+			}
+		}
+		if len(r.waiters[taskID]) == 0 {
+			delete(r.waiters, taskID)
+		}
+	}
+}
+
+// Wait for the webhook delivery of a submitted task.
+//
+// If no delivery arrives within fallbackAfter, the task is also polled with the given options,
+// and whichever of the delivery or polling finishes first provides the result. Polling that fails
+// without the task reaching a terminal status, e.g. on a network error or ErrMaxWaitExceeded, is
+// retried after fallbackAfter while the delivery is still awaited, until ctx is done. A zero
+// fallbackAfter disables polling. Errors are the same as those returned by bfl.Poll.
+func Wait[T bfl.Result, D bfl.Details](ctx context.Context, r *Registry, c *bfl.Client, ar *bfl.AsyncResponse, fallbackAfter time.Duration, opts ...bfl.PollOption) (*bfl.ResultResponse[T, D], error) {
+	deliveries, unsubscribe := r.subscribe(ar.ID)
+	defer unsubscribe()
+	defer c.ReleaseTask(ar.ID)
+
+	var fallback <-chan time.Time
+	if fallbackAfter > 0 {
+		timer := time.NewTimer(fallbackAfter)
+		defer timer.Stop()
+		fallback = timer.C
+	}
+	type outcome struct {
+		res *bfl.ResultResponse[T, D]
+		err error
+	}
+	polled := make(chan outcome, 1)
+	pollCtx, cancelPoll := context.WithCancel(ctx)
+	defer cancelPoll()
+	// The slot of the task is released by Wait, which may keep waiting for the delivery after
+	// polling fails.
+	pollOpts := append(slices.Clip(opts), bfl.PollKeepingSlot())
+
+	var pollErr error
+	for {
+		select {
+		case body := <-deliveries:
+			var res bfl.ResultResponse[T, D]
+			if err := json.Unmarshal(body, &res); err != nil {
+				return nil, err
+			}
+			if res.ID == "" {
+				res.ID = ar.ID
+			}
+			if err := res.Err(); err != nil {
+				return nil, err
+			}
+			return &res, nil
+		case <-fallback:
+			fallback = nil
+			go func() {
+				res, err := bfl.Poll[T, D](pollCtx, c, ar, pollOpts...)
+				polled <- outcome{res, err}
+			}()
+		case o := <-polled:
+			if o.err == nil || terminal(o.err) {
+				return o.res, o.err
+			}
+			pollErr = o.err
+			fallback = time.After(fallbackAfter)
+		case <-ctx.Done():
+			if pollErr != nil {
+				return nil, errors.Join(ctx.Err(), pollErr)
+			}
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Whether a polling error reports the final status of the task.
+func terminal(err error) bool {
+	var moderationErr *bfl.ModerationError
+	var failedErr *bfl.TaskFailedError
+	var notFoundErr *bfl.TaskNotFoundError
+	return errors.As(err, &moderationErr) || errors.As(err, &failedErr) || errors.As(err, &notFoundErr)
+}
+
+// Wait for the webhook delivery of an image generation task. See Wait.
+func WaitGenerate(ctx context.Context, r *Registry, c *bfl.Client, ar *bfl.AsyncResponse, fallbackAfter time.Duration, opts ...bfl.PollOption) (*GenerateResponse, error) {
+	return Wait[*bfl.GenerateResult, *bfl.GenerateDetails](ctx, r, c, ar, fallbackAfter, opts...)
+}
+
+// Wait for the webhook delivery of a finetuning task. See Wait.
+func WaitFinetune(ctx context.Context, r *Registry, c *bfl.Client, ar *bfl.AsyncResponse, fallbackAfter time.Duration, opts ...bfl.PollOption) (*FinetuneResponse, error) {
+	return Wait[*bfl.FinetuneResult, *bfl.FinetuneDetails](ctx, r, c, ar, fallbackAfter, opts...)
+}
+
+// Wait in the background for the webhook delivery of a submitted task, returning a handle whose
+// Wait and Done report the outcome. See Wait for the fallback to polling.
+func Track[T bfl.Result, D bfl.Details](ctx context.Context, r *Registry, c *bfl.Client, ar *bfl.AsyncResponse, fallbackAfter time.Duration, opts ...bfl.PollOption) *bfl.Task[T, D] {
+	return bfl.TrackWith(ctx, c, ar, func(ctx context.Context) (*bfl.ResultResponse[T, D], error) {
+		return Wait[T, D](ctx, r, c, ar, fallbackAfter, opts...)
+	})
+}
+
+// Submit an async task and wait in the background for its webhook delivery. See Track.
+// Cancelling ctx only affects the submission; use Stop on the task to stop waiting.
+func Submit[T bfl.Result, D bfl.Details](ctx context.Context, r *Registry, c *bfl.Client, task bfl.AsyncTask, fallbackAfter time.Duration, opts ...bfl.PollOption) (*bfl.Task[T, D], error) {
+	ar, err := c.AsyncRequest(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+	return Track[T, D](ctx, r, c, ar, fallbackAfter, opts...), nil
+}
+
+// Submit an image generation task and wait in the background for its webhook delivery. See Submit.
+func SubmitGenerate(ctx context.Context, r *Registry, c *bfl.Client, task bfl.GenerateTask, fallbackAfter time.Duration, opts ...bfl.PollOption) (*bfl.GenerationTask, error) {
+	return Submit[*bfl.GenerateResult, *bfl.GenerateDetails](ctx, r, c, task, fallbackAfter, opts...)
+}
+
+// Submit a finetuning task and wait in the background for its webhook delivery. See Submit.
+func SubmitFinetune(ctx context.Context, r *Registry, c *bfl.Client, task bfl.FinetuneTask, fallbackAfter time.Duration, opts ...bfl.PollOption) (*bfl.FinetuningTask, error) {
+	return Submit[*bfl.FinetuneResult, *bfl.FinetuneDetails](ctx, r, c, task, fallbackAfter, opts...)
+}
@@ -0,0 +1,277 @@
+// Package webhook receives task completion notifications sent by the BFL API to the
+// webhook URL configured on a task.
+//
+// The BFL API does not document how deliveries are authenticated, so checking that a delivery
+// comes from the API is left to a Verifier, e.g. one comparing a token set by a proxy in front of
+// the handler. HMACVerifier checks the signatures produced by Sign, a scheme of this package for
+// deliveries relayed through infrastructure you control, not a format used by the API.
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Kodlak15/bfl-go/bfl"
+)
+
+// Headers carrying the signature checked by HMACVerifier.
+const (
+	signatureHeader = "X-Webhook-Signature"
+	timestampHeader = "X-Webhook-Timestamp"
+)
+
+var (
+	ErrNoVerifier       = errors.New("webhook verifier is not configured")
+	ErrMissingSignature = errors.New("missing webhook signature")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrStaleTimestamp   = errors.New("webhook timestamp outside of tolerance")
+	ErrReplayed         = errors.New("webhook delivery already received")
+)
+
+// Checks that a delivery, given its headers and body, was sent by a trusted party.
+type Verifier func(header http.Header, body []byte) error
+
+// A verifier for deliveries signed with Sign: an HMAC-SHA256 of the Unix timestamp, a dot and the
+// body, keyed with the secret, sent along with the timestamp in the headers set by SignedHeader.
+// Deliveries signed more than tolerance away from now are rejected; a zero tolerance means 5 minutes.
+func HMACVerifier(secret string, tolerance time.Duration) Verifier {
+	if tolerance <= 0 {
+		tolerance = 5 * time.Minute
+	}
+	return func(header http.Header, body []byte) error {
+		signature := header.Get(signatureHeader)
+		timestamp := header.Get(timestampHeader)
+		if signature == "" || timestamp == "" {
+			return ErrMissingSignature
+		}
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return ErrStaleTimestamp
+		}
+		signedAt := time.Unix(unix, 0)
+		if !hmac.Equal([]byte(signature), []byte(Sign(secret, signedAt, body))) {
+			return ErrInvalidSignature
+		}
+		if age := time.Since(signedAt); age > tolerance || age < -tolerance {
+			return ErrStaleTimestamp
+		}
+		return nil
+	}
+}
+
+// Compute the signature of a delivery, in the form "sha256=<hex>". See HMACVerifier.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// The headers of a delivery signed with the given secret, as checked by HMACVerifier.
+func SignedHeader(secret string, timestamp time.Time, body []byte) http.Header {
+	header := make(http.Header)
+	header.Set(signatureHeader, Sign(secret, timestamp, body))
+	header.Set(timestampHeader, strconv.FormatInt(timestamp.Unix(), 10))
+	header.Set("Content-Type", "application/json")
+	return header
+}
+
+type GenerateResponse = bfl.ResultResponse[*bfl.GenerateResult, *bfl.GenerateDetails]
+
+type FinetuneResponse = bfl.ResultResponse[*bfl.FinetuneResult, *bfl.FinetuneDetails]
+
+// An http.Handler receiving webhook deliveries for generation and finetuning tasks.
+//
+// Deliveries are checked by the verifier, decoded, and dispatched to the callback matching the
+// kind of task. A callback returning an error makes the handler respond with HTTP 500 so that the
+// delivery can be retried.
+//
+// Deliveries are rejected when no verifier is configured, unless InsecureSkipVerify is set.
+type Handler struct {
+	// Checks that deliveries were sent by a trusted party.
+	Verifier Verifier
+	// Accept deliveries without verifying them or checking whether they were already received.
+	// Only meant for local development, as anyone can then send deliveries.
+	InsecureSkipVerify bool
+	// How long handled deliveries are remembered to reject replays.
+	// Default: 10 minutes.
+	ReplayWindow time.Duration
+	// Maximum size of a delivery body.
+	// Default: 1 MiB.
+	MaxBodyBytes int64
+	// Called for deliveries of generation tasks.
+	OnGenerate func(ctx context.Context, res *GenerateResponse) error
+	// Called for deliveries of finetuning tasks.
+	OnFinetune func(ctx context.Context, res *FinetuneResponse) error
+	// Called for deliveries whose kind of task cannot be determined from the result,
+	// e.g. moderated or failed tasks. Receives the task ID, its status and the raw body.
+	OnOther func(ctx context.Context, taskID string, status bfl.StatusResponse, body []byte) error
+	// Registry receiving every verified delivery, to complete calls to Wait.
+	Registry *Registry
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// Create a handler checking deliveries with the given verifier.
+func NewHandler(verifier Verifier) *Handler {
+	return &Handler{Verifier: verifier}
+}
+
+func (h *Handler) replayWindow() time.Duration {
+	if h.ReplayWindow > 0 {
+		return h.ReplayWindow
+	}
+	return 10 * time.Minute
+}
+
+// Check a delivery with the verifier, and that it was not received before.
+// Fails with ErrNoVerifier when no verifier is configured, unless InsecureSkipVerify is set.
+func (h *Handler) Verify(header http.Header, body []byte) error {
+	if h.InsecureSkipVerify {
+		return nil
+	}
+	if h.Verifier == nil {
+		return ErrNoVerifier
+	}
+	if err := h.Verifier(header, body); err != nil {
+		return err
+	}
+	return h.remember(deliveryKey(body), time.Now())
+}
+
+// Identifies a delivery by the checksum of its body.
+func deliveryKey(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// Record a delivery, failing if it was already seen within the replay window.
+func (h *Handler) remember(key string, now time.Time) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.seen == nil {
+		h.seen = make(map[string]time.Time)
+	}
+	for k, at := range h.seen {
+		if now.Sub(at) > h.replayWindow() {
+			delete(h.seen, k)
+		}
+	}
+	if _, ok := h.seen[key]; ok {
+		return ErrReplayed
+	}
+	h.seen[key] = now
+	return nil
+}
+
+// Forget a delivery, so that it is accepted again when it is retried.
+func (h *Handler) forget(key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.seen, key)
+}
+
+// The fields shared by all deliveries, used to determine the kind of task.
+type envelope struct {
+	ID     string             `json:"id"`
+	TaskID string             `json:"task_id"`
+	Status bfl.StatusResponse `json:"status"`
+	Result json.RawMessage    `json:"result"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	maxBodyBytes := h.MaxBodyBytes
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = 1 << 20
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		http.Error(w, "unable to read body", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err := h.Verify(r.Header, body); err != nil {
+		code := http.StatusUnauthorized
+		if errors.Is(err, ErrNoVerifier) {
+			code = http.StatusInternalServerError
+		}
+		http.Error(w, err.Error(), code)
+		return
+	}
+	if err := h.dispatch(r.Context(), body); err != nil {
+		if !h.InsecureSkipVerify {
+			h.forget(deliveryKey(body))
+		}
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Decode a verified delivery and pass it to the matching callback.
+func (h *Handler) dispatch(ctx context.Context, body []byte) error {
+	var env envelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		return err
+	}
+	if env.ID == "" {
+		env.ID = env.TaskID
+	}
+	if h.Registry != nil && env.ID != "" {
+		h.Registry.Deliver(env.ID, body)
+	}
+	// Results that are not objects are dispatched as other deliveries.
+	var result map[string]json.RawMessage
+	_ = json.Unmarshal(env.Result, &result)
+	switch {
+	case result["sample"] != nil && h.OnGenerate != nil:
+		res, err := decode[*bfl.GenerateResult, *bfl.GenerateDetails](body, env.ID)
+		if err != nil {
+			return err
+		}
+		return h.OnGenerate(ctx, res)
+	case result["finetune_id"] != nil && h.OnFinetune != nil:
+		res, err := decode[*bfl.FinetuneResult, *bfl.FinetuneDetails](body, env.ID)
+		if err != nil {
+			return err
+		}
+		return h.OnFinetune(ctx, res)
+	case h.OnOther != nil:
+		return h.OnOther(ctx, env.ID, env.Status, body)
+	default:
+		return nil
+	}
+}
+
+// Decode a delivery into the result response of the given kind of task.
+func decode[T bfl.Result, D bfl.Details](body []byte, taskID string) (*bfl.ResultResponse[T, D], error) {
+	var res bfl.ResultResponse[T, D]
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, err
+	}
+	if res.ID == "" {
+		res.ID = taskID
+	}
+	return &res, nil
+}